@@ -7,11 +7,16 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/OnatArslan/devlog/internal/httpx"
+	"github.com/OnatArslan/devlog/internal/keyring"
+	"github.com/OnatArslan/devlog/internal/mailer"
+	"github.com/OnatArslan/devlog/internal/passcheck"
 	"github.com/OnatArslan/devlog/internal/post"
-	"github.com/OnatArslan/devlog/internal/sqlc"
 	"github.com/OnatArslan/devlog/internal/user"
 	"github.com/OnatArslan/devlog/internal/validatorx"
 	"github.com/go-chi/chi/v5"
@@ -21,8 +26,10 @@ import (
 )
 
 func main() {
-	// Create a root context used during app bootstrapping.
-	ctx := context.Background()
+	// The root context is cancelled on SIGINT or SIGTERM, which stops the background jobs and
+	// shuts the server down.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Load env variables
 	if err := godotenv.Load(); err != nil {
@@ -37,9 +44,6 @@ func main() {
 	// Ensure database resources are released on process shutdown.
 	defer pool.Close()
 
-	// Create sqlc queries struct
-	queries := sqlc.New(pool)
-
 	// Create validator object
 	validate := validatorx.New()
 
@@ -49,20 +53,79 @@ func main() {
 	// A good base middleware stack
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
+	r.Use(user.ClientInfoMiddleware)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(20 * time.Second))
 
+	// Load the JWT signing keys before anything can issue or verify tokens.
+	keys, err := newKeyring()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Pick the password hashing algorithm and its cost parameters.
+	hasher, err := newPasswordHasher()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Screen new passwords against a local breach list when one is configured.
+	var breaches passcheck.BreachChecker
+	if path := os.Getenv("BREACHED_PASSWORDS_FILE"); path != "" {
+		checker, err := passcheck.OpenFileBreachChecker(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer checker.Close()
+		breaches = checker
+	} else {
+		log.Println("BREACHED_PASSWORDS_FILE not set, new passwords are not checked against known breaches")
+	}
+
+	// Deleted accounts stay restorable for ACCOUNT_DELETION_GRACE (a Go duration, 720h by default).
+	var deletionGrace time.Duration
+	if raw := os.Getenv("ACCOUNT_DELETION_GRACE"); raw != "" {
+		deletionGrace, err = time.ParseDuration(raw)
+		if err != nil || deletionGrace <= 0 {
+			log.Fatalf("ACCOUNT_DELETION_GRACE must be a positive duration such as 720h, got %q", raw)
+		}
+	}
+
+	// Private instances can restrict signup to invited users or close it entirely.
+	registrationMode := user.RegistrationMode(os.Getenv("REGISTRATION_MODE"))
+	if registrationMode == "" {
+		registrationMode = user.RegistrationOpen
+	}
+	if !registrationMode.Valid() {
+		log.Fatalf("REGISTRATION_MODE must be open, invite-only or closed, got %q", registrationMode)
+	}
+
+	// Publish verification keys so other services can check devlog tokens without a shared secret.
+	r.Get("/.well-known/jwks.json", keys.ServeJWKS)
+
 	// DOMAINS --------- ----------- -----------
 	// User domain
 	// Wire repository, service, validations, and HTTP handlers for user module.
-	userRepo := user.NewUserRepository(queries)
-	userSvc := user.NewUserService(userRepo)
+	userRepo := user.NewUserRepository(pool)
+	userSvc := user.NewUserService(userRepo, user.Config{
+		Mailer:              newMailer(),
+		AppURL:              os.Getenv("APP_URL"),
+		Keys:                keys,
+		PasswordHasher:      hasher,
+		BreachChecker:       breaches,
+		RegistrationMode:    registrationMode,
+		DeletionGracePeriod: deletionGrace,
+	})
+	// Hard-delete accounts whose deletion grace period has run out.
+	go userSvc.RunDeletionPurger(ctx, time.Hour)
 	userHandler := user.NewUserHandler(userSvc, validate)
 
-	postRepo := post.NewPostRepository(queries)
+	postRepo := post.NewPostRepository(pool)
 	postService := post.NewPostService(postRepo)
-	postHandler := post.NewPostHandler(postService, validate, userHandler.AuthMiddleware)
+	// Publish scheduled posts, catching up on any that came due while the API was down.
+	go postService.RunScheduledPublisher(ctx, time.Minute)
+	postHandler := post.NewPostHandler(postService, validate, userHandler.AuthMiddleware, userHandler.OptionalAuthMiddleware)
 
 	// We connect base router for api/v1
 	r.Route("/api/v1", func(r chi.Router) {
@@ -74,9 +137,16 @@ func main() {
 			})
 		})
 
-		// Mount user-related endpoints under /api/v1/users.
-		r.Mount("/users", userHandler.Routes(chi.NewRouter()))
+		// Mount user-related endpoints under /api/v1/users. Author post listings live in the
+		// post module but share the /users/{username} prefix with public profiles.
+		r.Route("/users", func(r chi.Router) {
+			userHandler.Routes(r)
+			r.With(userHandler.OptionalAuthMiddleware).Get("/{username}/posts", postHandler.GetPostsByAuthor)
+		})
 		r.Mount("/posts", postHandler.Routes(chi.NewRouter()))
+		r.Mount("/feed", postHandler.FeedRoutes(chi.NewRouter()))
+		r.Mount("/tags", postHandler.TagRoutes(chi.NewRouter()))
+		r.Mount("/admin", userHandler.AdminRoutes(chi.NewRouter()))
 
 	})
 
@@ -95,9 +165,111 @@ func main() {
 		addr = ":" + addr
 	}
 
+	srv := &http.Server{Addr: addr, Handler: r}
+
+	// Stop accepting connections once a signal arrives and let in-flight requests finish.
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("server shutdown: %v", err)
+		}
+	}()
+
 	// Start the HTTP server and terminate on fatal listen errors.
 	fmt.Printf("server listening PORT %s\n", addr)
-	if err := http.ListenAndServe(addr, r); err != nil {
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal(err)
 	}
+	<-shutdownDone
+	log.Println("server stopped")
+}
+
+// shutdownTimeout bounds how long in-flight requests may take to finish after a shutdown signal.
+const shutdownTimeout = 25 * time.Second
+
+// newMailer sends through SMTP when SMTP_ADDR is configured and keeps messages in memory otherwise.
+func newMailer() mailer.Mailer {
+	addr := os.Getenv("SMTP_ADDR")
+	if addr == "" {
+		log.Println("SMTP_ADDR not set, emails will be kept in memory and not delivered")
+		return mailer.NewMemoryMailer()
+	}
+	return mailer.NewSMTPMailer(addr, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_FROM"))
+}
+
+// newKeyring loads JWT keys from JWT_KEYS_DIR, signing with JWT_ACTIVE_KID. Without a key
+// directory it derives the signing key from JWT_SECRET, which every replica shares. An ephemeral
+// key, which invalidates all tokens on restart and differs per replica, is only used when
+// JWT_EPHEMERAL_KEY is set for local development.
+func newKeyring() (*keyring.Keyring, error) {
+	if dir := os.Getenv("JWT_KEYS_DIR"); dir != "" {
+		return keyring.LoadDir(dir, os.Getenv("JWT_ACTIVE_KID"))
+	}
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		return keyring.FromSecret(secret)
+	}
+
+	ephemeral, _ := strconv.ParseBool(os.Getenv("JWT_EPHEMERAL_KEY"))
+	if !ephemeral {
+		return nil, errors.New("set JWT_KEYS_DIR or JWT_SECRET, or JWT_EPHEMERAL_KEY=true for local development")
+	}
+	log.Println("JWT_EPHEMERAL_KEY set, signing tokens with an ephemeral key")
+	return keyring.Ephemeral()
+}
+
+// newPasswordHasher builds the hasher named by PASSWORD_HASHER ("argon2id" by default, or "bcrypt").
+// ARGON2_MEMORY_KIB, ARGON2_ITERATIONS, ARGON2_PARALLELISM and BCRYPT_COST override the defaults.
+// Changing any of them makes existing hashes get upgraded on each user's next sign-in.
+func newPasswordHasher() (user.PasswordHasher, error) {
+	switch algo := os.Getenv("PASSWORD_HASHER"); algo {
+	case "", "argon2id":
+		params := user.DefaultArgon2idParams
+		memory, err := envUint("ARGON2_MEMORY_KIB", uint64(params.Memory), 32)
+		if err != nil {
+			return nil, err
+		}
+		iterations, err := envUint("ARGON2_ITERATIONS", uint64(params.Iterations), 32)
+		if err != nil {
+			return nil, err
+		}
+		parallelism, err := envUint("ARGON2_PARALLELISM", uint64(params.Parallelism), 8)
+		if err != nil {
+			return nil, err
+		}
+		params.Memory = uint32(memory)
+		params.Iterations = uint32(iterations)
+		params.Parallelism = uint8(parallelism)
+		if params.Memory < 8*uint32(params.Parallelism) || params.Iterations == 0 || params.Parallelism == 0 {
+			return nil, errors.New("argon2id parameters out of range")
+		}
+		return user.NewArgon2idHasher(params), nil
+	case "bcrypt":
+		cost, err := envUint("BCRYPT_COST", 12, 8)
+		if err != nil {
+			return nil, err
+		}
+		if cost < 10 || cost > 31 {
+			return nil, fmt.Errorf("BCRYPT_COST must be between 10 and 31, got %d", cost)
+		}
+		return user.NewBcryptHasher(int(cost)), nil
+	default:
+		return nil, fmt.Errorf("unknown PASSWORD_HASHER %q", algo)
+	}
+}
+
+// envUint reads an unsigned integer of the given bit size from name, or returns def when unset.
+func envUint(name string, def uint64, bitSize int) (uint64, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseUint(raw, 10, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return v, nil
 }
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS sessions(
+    id UUID PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ,
+    CONSTRAINT fk_sessions_users FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+
+-- A session is a refresh token family, so give every family that is still alive a session row.
+INSERT INTO sessions (id, user_id, created_at, last_seen_at)
+SELECT family_id, user_id, min(created_at), max(created_at)
+FROM refresh_tokens
+WHERE revoked_at IS NULL AND expires_at > now()
+GROUP BY family_id, user_id
+ON CONFLICT (id) DO NOTHING;
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS sessions;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS display_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS bio TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS website TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS social_links JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_posts_author_id_created_at ON posts(author_id, created_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_posts_author_id_created_at;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS social_links,
+    DROP COLUMN IF EXISTS website,
+    DROP COLUMN IF EXISTS bio,
+    DROP COLUMN IF EXISTS display_name;
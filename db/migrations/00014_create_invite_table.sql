@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS invites(
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    created_by BIGINT NOT NULL,
+    code_prefix TEXT NOT NULL,
+    code_hash TEXT NOT NULL UNIQUE,
+    email TEXT,
+    max_uses INT NOT NULL DEFAULT 1 CHECK (max_uses > 0),
+    use_count INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_invites_users FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+CREATE INDEX IF NOT EXISTS idx_invites_created_by ON invites(created_by);
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS invites;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS follows(
+    follower_id BIGINT NOT NULL,
+    followee_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (follower_id, followee_id),
+    CONSTRAINT chk_follows_not_self CHECK (follower_id <> followee_id),
+    CONSTRAINT fk_follows_follower_users FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_follows_followee_users FOREIGN KEY (followee_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+CREATE INDEX IF NOT EXISTS idx_follows_followee_id_created_at ON follows(followee_id, created_at DESC);
+
+-- The feed pages through each followed author's posts by (created_at, id), so the tie-breaker
+-- has to be part of the index for the per-author scans to stay index-only in order.
+CREATE INDEX IF NOT EXISTS idx_posts_author_id_created_at_id ON posts(author_id, created_at DESC, id DESC);
+DROP INDEX IF EXISTS idx_posts_author_id_created_at;
+
+-- +goose Down
+CREATE INDEX IF NOT EXISTS idx_posts_author_id_created_at ON posts(author_id, created_at DESC);
+DROP INDEX IF EXISTS idx_posts_author_id_created_at_id;
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS follows;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS account_deletions(
+    user_id BIGINT PRIMARY KEY,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    purge_after TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_account_deletions_users FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+CREATE INDEX IF NOT EXISTS idx_account_deletions_purge_after ON account_deletions(purge_after);
+
+-- A purged account takes its posts with it. Every other table referencing users already cascades,
+-- so a hard delete leaves no personal data behind.
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS fk_posts_users,
+    ADD CONSTRAINT fk_posts_users FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE;
+
+-- +goose Down
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS fk_posts_users,
+    ADD CONSTRAINT fk_posts_users FOREIGN KEY (author_id) REFERENCES users(id);
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS account_deletions;
+-- +goose StatementEnd
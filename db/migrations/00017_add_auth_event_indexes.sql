@@ -0,0 +1,11 @@
+-- +goose Up
+-- The admin audit search filters by event type or IP over a time range, and the unfiltered
+-- listing pages through everything newest first.
+CREATE INDEX IF NOT EXISTS idx_auth_events_created_at ON auth_events(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_auth_events_event_type_created_at ON auth_events(event_type, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_auth_events_ip_created_at ON auth_events(ip, created_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_auth_events_ip_created_at;
+DROP INDEX IF EXISTS idx_auth_events_event_type_created_at;
+DROP INDEX IF EXISTS idx_auth_events_created_at;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS blocks(
+    blocker_id BIGINT NOT NULL,
+    blocked_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CONSTRAINT chk_blocks_not_self CHECK (blocker_id <> blocked_id),
+    CONSTRAINT fk_blocks_blocker_users FOREIGN KEY (blocker_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_blocks_blocked_users FOREIGN KEY (blocked_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+CREATE INDEX IF NOT EXISTS idx_blocks_blocked_id ON blocks(blocked_id);
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS mutes(
+    muter_id BIGINT NOT NULL,
+    muted_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (muter_id, muted_id),
+    CONSTRAINT chk_mutes_not_self CHECK (muter_id <> muted_id),
+    CONSTRAINT fk_mutes_muter_users FOREIGN KEY (muter_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_mutes_muted_users FOREIGN KEY (muted_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+CREATE INDEX IF NOT EXISTS idx_mutes_muted_id ON mutes(muted_id);
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS mutes;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS blocks;
+-- +goose StatementEnd
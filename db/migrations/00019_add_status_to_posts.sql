@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published',
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ,
+    ADD CONSTRAINT posts_status_check CHECK (status IN ('draft', 'published', 'archived'));
+
+-- Posts written before the lifecycle existed were public from the moment they were created.
+UPDATE posts SET published_at = created_at WHERE published_at IS NULL;
+
+-- New posts start out as drafts.
+ALTER TABLE posts ALTER COLUMN status SET DEFAULT 'draft';
+
+CREATE INDEX IF NOT EXISTS idx_posts_published_created_at ON posts(created_at DESC) WHERE status = 'published';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_posts_published_created_at;
+
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_status_check,
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS status;
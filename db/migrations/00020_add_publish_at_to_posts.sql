@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+
+-- The scheduler only ever looks for drafts whose publish time has come.
+CREATE INDEX IF NOT EXISTS idx_posts_publish_at ON posts(publish_at) WHERE status = 'draft' AND publish_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_posts_publish_at;
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS publish_at;
@@ -0,0 +1,34 @@
+-- +goose Up
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS slug TEXT;
+
+-- Existing posts get an ASCII slug from their title, suffixed with the ID so that no two collide.
+UPDATE posts SET slug = COALESCE(
+    NULLIF(trim(BOTH '-' FROM left(regexp_replace(lower(title), '[^a-z0-9]+', '-', 'g'), 80)), ''),
+    'post'
+) || '-' || id
+WHERE slug IS NULL;
+
+ALTER TABLE posts
+    ALTER COLUMN slug SET NOT NULL,
+    ADD CONSTRAINT posts_slug_key UNIQUE (slug);
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS post_slug_history(
+    slug TEXT PRIMARY KEY,
+    post_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_post_slug_history_posts FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+CREATE INDEX IF NOT EXISTS idx_post_slug_history_post_id ON post_slug_history(post_id);
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS post_slug_history;
+-- +goose StatementEnd
+
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_slug_key,
+    DROP COLUMN IF EXISTS slug;
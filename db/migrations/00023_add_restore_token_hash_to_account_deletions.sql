@@ -0,0 +1,9 @@
+-- +goose Up
+-- Binds each emailed restore link to the deletion it was sent for. Deletions scheduled before this
+-- column existed keep a NULL hash and are restored by the unbound link they were sent.
+ALTER TABLE account_deletions
+    ADD COLUMN IF NOT EXISTS restore_token_hash TEXT;
+
+-- +goose Down
+ALTER TABLE account_deletions
+    DROP COLUMN IF EXISTS restore_token_hash;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Slug lookups by prefix use LIKE, which can only use a btree index built with text_pattern_ops
+-- unless the database runs under the C collation.
+CREATE INDEX IF NOT EXISTS idx_posts_slug_pattern ON posts(slug text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_post_slug_history_slug_pattern ON post_slug_history(slug text_pattern_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_post_slug_history_slug_pattern;
+DROP INDEX IF EXISTS idx_posts_slug_pattern;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Listings and the feed are ordered by (published_at, id), so posts scheduled or published long
+-- after they were written show up where readers expect them.
+CREATE INDEX IF NOT EXISTS idx_posts_published_at_id ON posts(published_at DESC, id DESC) WHERE status = 'published';
+DROP INDEX IF EXISTS idx_posts_published_created_at;
+
+-- Author pages and the per-author feed scans. Drafts have no published_at and sort first, which
+-- matches the order the author sees on their own page.
+CREATE INDEX IF NOT EXISTS idx_posts_author_id_published_at_id ON posts(author_id, published_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_posts_author_id_published_at_id;
+
+CREATE INDEX IF NOT EXISTS idx_posts_published_created_at ON posts(created_at DESC) WHERE status = 'published';
+DROP INDEX IF EXISTS idx_posts_published_at_id;
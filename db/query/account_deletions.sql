@@ -0,0 +1,22 @@
+-- name: ScheduleAccountDeletion :exec
+INSERT INTO account_deletions (user_id, purge_after, restore_token_hash)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE SET
+  requested_at = now(),
+  purge_after = EXCLUDED.purge_after,
+  restore_token_hash = EXCLUDED.restore_token_hash;
+
+-- name: CancelAccountDeletion :execrows
+-- Only the link sent for the pending deletion restores it. Links without a nonce only match
+-- deletions scheduled before restore links were bound.
+DELETE FROM account_deletions
+WHERE user_id = @user_id
+  AND restore_token_hash IS NOT DISTINCT FROM sqlc.narg(restore_token_hash)::text
+  AND purge_after > now();
+
+-- name: PurgeDeletedAccounts :execrows
+-- Hard-deletes accounts whose grace period is over. The account_deletions row and everything
+-- else the user owns goes with the cascade.
+DELETE FROM users u
+USING account_deletions d
+WHERE d.user_id = u.id AND d.purge_after <= now() AND u.is_active = FALSE;
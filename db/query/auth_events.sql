@@ -0,0 +1,25 @@
+-- name: CreateAuthEvent :exec
+INSERT INTO auth_events (user_id, event_type, ip, user_agent, request_id, metadata)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListUserAuthEvents :many
+SELECT * FROM auth_events
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListUserAuthEventsPage :many
+SELECT * FROM auth_events
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: SearchAuthEvents :many
+SELECT * FROM auth_events
+WHERE (sqlc.narg(user_id)::bigint IS NULL OR user_id = sqlc.narg(user_id))
+  AND (sqlc.narg(event_type)::text IS NULL OR event_type = sqlc.narg(event_type))
+  AND (sqlc.narg(ip)::text IS NULL OR ip = sqlc.narg(ip))
+  AND (sqlc.narg(request_id)::text IS NULL OR request_id = sqlc.narg(request_id))
+  AND (sqlc.narg(since)::timestamptz IS NULL OR created_at >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamptz IS NULL OR created_at < sqlc.narg(until))
+ORDER BY created_at DESC, id DESC
+LIMIT @page_limit OFFSET @page_offset;
@@ -0,0 +1,23 @@
+-- name: GetAuthThrottles :many
+SELECT * FROM auth_throttles
+WHERE key = ANY(@keys::text[]);
+
+-- name: RecordAuthFailure :one
+-- Counts a failure for key; a streak older than reset_before starts over from one.
+INSERT INTO auth_throttles (key, failures, last_failure_at)
+VALUES (@key, 1, now())
+ON CONFLICT (key) DO UPDATE SET
+    failures = CASE
+        WHEN auth_throttles.last_failure_at < @reset_before THEN 1
+        ELSE auth_throttles.failures + 1
+    END,
+    last_failure_at = now()
+RETURNING *;
+
+-- name: LockAuthThrottle :exec
+UPDATE auth_throttles SET locked_until = $2
+WHERE key = $1;
+
+-- name: ClearAuthThrottle :exec
+DELETE FROM auth_throttles
+WHERE key = $1;
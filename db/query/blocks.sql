@@ -0,0 +1,23 @@
+-- name: BlockUser :exec
+INSERT INTO blocks (blocker_id, blocked_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: UnblockUser :exec
+DELETE FROM blocks
+WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: ListBlockedUsers :many
+SELECT u.username, u.display_name, b.created_at
+FROM blocks b
+JOIN users u ON u.id = b.blocked_id
+WHERE b.blocker_id = $1
+ORDER BY b.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: IsBlockedBetween :one
+SELECT EXISTS (
+  SELECT 1 FROM blocks
+  WHERE (blocker_id = @user_id AND blocked_id = @other_id)
+     OR (blocker_id = @other_id AND blocked_id = @user_id)
+);
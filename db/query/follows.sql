@@ -0,0 +1,57 @@
+-- name: FollowUser :exec
+INSERT INTO follows (follower_id, followee_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: UnfollowUser :exec
+DELETE FROM follows
+WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: ListFollowers :many
+-- Accounts that blocked the viewer are left out.
+SELECT u.username, u.display_name, f.created_at AS followed_at
+FROM follows f
+JOIN users u ON u.id = f.follower_id
+WHERE f.followee_id = @followee_id AND u.is_active = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = u.id AND b.blocked_id = @viewer_id
+  )
+ORDER BY f.created_at DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: ListFollowing :many
+-- Accounts that blocked the viewer are left out.
+SELECT u.username, u.display_name, f.created_at AS followed_at
+FROM follows f
+JOIN users u ON u.id = f.followee_id
+WHERE f.follower_id = @follower_id AND u.is_active = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = u.id AND b.blocked_id = @viewer_id
+  )
+ORDER BY f.created_at DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountFollowers :one
+SELECT count(*) FROM follows f
+JOIN users u ON u.id = f.follower_id
+WHERE f.followee_id = @followee_id AND u.is_active = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = u.id AND b.blocked_id = @viewer_id
+  );
+
+-- name: CountFollowing :one
+SELECT count(*) FROM follows f
+JOIN users u ON u.id = f.followee_id
+WHERE f.follower_id = @follower_id AND u.is_active = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = u.id AND b.blocked_id = @viewer_id
+  );
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = @user_id AND followee_id = @other_id)
+   OR (follower_id = @other_id AND followee_id = @user_id);
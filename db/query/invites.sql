@@ -0,0 +1,23 @@
+-- name: CreateInvite :one
+INSERT INTO invites (created_by, code_prefix, code_hash, email, max_uses, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListInvitesByCreator :many
+SELECT * FROM invites
+WHERE created_by = $1 AND revoked_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: RevokeInvite :execrows
+UPDATE invites SET revoked_at = now()
+WHERE id = $1 AND created_by = $2 AND revoked_at IS NULL;
+
+-- name: ConsumeInvite :one
+-- Claims one use of a live invite. Invites bound to an email only match that address.
+UPDATE invites SET use_count = use_count + 1
+WHERE code_hash = @code_hash
+  AND revoked_at IS NULL
+  AND use_count < max_uses
+  AND (expires_at IS NULL OR expires_at > now())
+  AND (email IS NULL OR lower(email) = lower(@email::text))
+RETURNING id;
@@ -0,0 +1,16 @@
+-- name: CreateMagicLinkToken :exec
+INSERT INTO magic_link_tokens (user_id, token_hash, client_hash, expires_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetMagicLinkTokenByHash :one
+SELECT * FROM magic_link_tokens
+WHERE token_hash = $1;
+
+-- name: UseMagicLinkToken :execrows
+-- Marks a token as used only if nobody used it first.
+UPDATE magic_link_tokens SET used_at = now()
+WHERE id = $1 AND used_at IS NULL;
+
+-- name: CountRecentMagicLinkTokens :one
+SELECT count(*) FROM magic_link_tokens
+WHERE user_id = $1 AND created_at > $2;
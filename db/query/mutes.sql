@@ -0,0 +1,16 @@
+-- name: MuteUser :exec
+INSERT INTO mutes (muter_id, muted_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: UnmuteUser :exec
+DELETE FROM mutes
+WHERE muter_id = $1 AND muted_id = $2;
+
+-- name: ListMutedUsers :many
+SELECT u.username, u.display_name, m.created_at
+FROM mutes m
+JOIN users u ON u.id = m.muted_id
+WHERE m.muter_id = $1
+ORDER BY m.created_at DESC
+LIMIT $2 OFFSET $3;
@@ -1,16 +1,261 @@
 -- name: CreatePost :one
-INSERT INTO posts (author_id, title, content)
-VALUES ($1, $2, $3)
+INSERT INTO posts (author_id, title, content, slug)
+VALUES ($1, $2, $3, $4)
 RETURNING *
 ;
 
 
 -- name: GetAllPosts :many
-SELECT p.*, u.username FROM posts p JOIN users u ON u.id = p.author_id
-ORDER BY p.created_at DESC
-LIMIT $1 OFFSET $2;
+-- Lists published posts and the viewer's own drafts and archived posts, leaving out authors the
+-- viewer blocked, muted or was blocked by. Anonymous readers pass a viewer ID of 0, which matches
+-- no author or relation.
+-- Posts are listed newest published first; drafts have no publish time and sort ahead of them.
+SELECT p.*, u.username,
+  COALESCE((
+    SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+    WHERE pt.post_id = p.id
+  ), '{}')::text[] AS tags
+FROM posts p JOIN users u ON u.id = p.author_id
+WHERE (p.status = 'published' OR p.author_id = @viewer_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE (b.blocker_id = p.author_id AND b.blocked_id = @viewer_id)
+       OR (b.blocker_id = @viewer_id AND b.blocked_id = p.author_id)
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM mutes m
+    WHERE m.muter_id = @viewer_id AND m.muted_id = p.author_id
+  )
+ORDER BY p.published_at DESC, p.id DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+
+-- name: GetPostsByTags :many
+-- GetAllPosts narrowed to posts tagged with any of @tags, or with all of them when @match_all is
+-- set. Matching starts from the tags and reads post_tags by tag, so only posts carrying one of them
+-- are looked at. @tags holds no duplicates, so a post carries all of them when it matches as many
+-- rows as there are tags.
+WITH tagged AS (
+  SELECT pt.post_id FROM tags t JOIN post_tags pt ON pt.tag_id = t.id
+  WHERE t.name = ANY(@tags::text[])
+  GROUP BY pt.post_id
+  HAVING NOT @match_all::boolean OR count(*) = cardinality(@tags::text[])
+)
+SELECT p.*, u.username,
+  COALESCE((
+    SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+    WHERE pt.post_id = p.id
+  ), '{}')::text[] AS tags
+FROM tagged
+JOIN posts p ON p.id = tagged.post_id
+JOIN users u ON u.id = p.author_id
+WHERE (p.status = 'published' OR p.author_id = @viewer_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE (b.blocker_id = p.author_id AND b.blocked_id = @viewer_id)
+       OR (b.blocker_id = @viewer_id AND b.blocked_id = p.author_id)
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM mutes m
+    WHERE m.muter_id = @viewer_id AND m.muted_id = p.author_id
+  )
+ORDER BY p.published_at DESC, p.id DESC
+LIMIT @page_limit OFFSET @page_offset;
 
 
 -- name: GetPostById :one
-SELECT p.*, u.username FROM posts p JOIN users u ON u.id = p.author_id
-WHERE p.id = $1;
+-- Drafts are only visible to their author; archived posts stay reachable by their link.
+SELECT p.*, u.username,
+  COALESCE((
+    SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+    WHERE pt.post_id = p.id
+  ), '{}')::text[] AS tags
+FROM posts p JOIN users u ON u.id = p.author_id
+WHERE p.id = @id
+  AND (p.status <> 'draft' OR p.author_id = @viewer_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = p.author_id AND b.blocked_id = @viewer_id
+  );
+
+
+-- name: GetPostBySlug :one
+-- Same visibility rules as GetPostById.
+SELECT p.*, u.username,
+  COALESCE((
+    SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+    WHERE pt.post_id = p.id
+  ), '{}')::text[] AS tags
+FROM posts p JOIN users u ON u.id = p.author_id
+WHERE p.slug = @slug
+  AND (p.status <> 'draft' OR p.author_id = @viewer_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = p.author_id AND b.blocked_id = @viewer_id
+  );
+
+
+-- name: GetSlugRedirect :one
+-- Resolves a slug a post used to have to the post's current slug, as long as the viewer may see the post.
+SELECT p.slug FROM post_slug_history h JOIN posts p ON p.id = h.post_id
+WHERE h.slug = @slug
+  AND (p.status <> 'draft' OR p.author_id = @viewer_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = p.author_id AND b.blocked_id = @viewer_id
+  );
+
+
+-- name: ListTakenSlugs :many
+-- Lists the slugs made of @base and an optional -N suffix that other posts use now or used before,
+-- so a new slug neither collides with a post nor steals another post's old links. A post may take
+-- back its own. Slugs hold no LIKE or regex metacharacters, so the prefix match can use the
+-- text_pattern_ops indexes and the regex only drops longer slugs such as go-tips for go.
+SELECT slug FROM posts
+WHERE slug LIKE @base::text || '%' AND slug ~ ('^' || @base::text || '(-[0-9]+)?$') AND id <> @post_id
+UNION
+SELECT slug FROM post_slug_history
+WHERE slug LIKE @base::text || '%' AND slug ~ ('^' || @base::text || '(-[0-9]+)?$') AND post_id <> @post_id;
+
+
+-- name: GetActiveAuthorID :one
+SELECT u.id FROM users u
+WHERE u.username = @username AND u.is_active = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = u.id AND b.blocked_id = @viewer_id
+  );
+
+
+-- name: GetPostsByAuthor :many
+-- Other readers only see published posts; the author also sees their drafts and archive.
+-- Ordered like GetAllPosts.
+SELECT p.*, u.username,
+  COALESCE((
+    SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+    WHERE pt.post_id = p.id
+  ), '{}')::text[] AS tags
+FROM posts p JOIN users u ON u.id = p.author_id
+WHERE p.author_id = @author_id
+  AND (p.status = 'published' OR p.author_id = @viewer_id)
+ORDER BY p.published_at DESC, p.id DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+
+-- name: GetFeed :many
+-- Takes at most one page from each followed author through the (author_id, published_at, id)
+-- index and merges those, so the cost grows with the number of follows, not with their history.
+SELECT p.id, p.author_id, p.title, p.content, p.updated_at, p.created_at, p.status, p.published_at, p.slug, u.username,
+  COALESCE((
+    SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+    WHERE pt.post_id = p.id
+  ), '{}')::text[] AS tags
+FROM follows f
+JOIN users u ON u.id = f.followee_id AND u.is_active = TRUE
+CROSS JOIN LATERAL (
+  SELECT ap.id, ap.author_id, ap.title, ap.content, ap.updated_at, ap.created_at, ap.status, ap.published_at, ap.slug FROM posts ap
+  WHERE ap.author_id = f.followee_id
+    AND ap.status = 'published'
+    AND (ap.published_at, ap.id) < (@before_published_at::timestamptz, @before_id::bigint)
+  ORDER BY ap.published_at DESC, ap.id DESC
+  LIMIT @page_size::int
+) p
+WHERE f.follower_id = @follower_id
+  AND NOT EXISTS (
+    SELECT 1 FROM mutes m
+    WHERE m.muter_id = @follower_id AND m.muted_id = f.followee_id
+  )
+ORDER BY p.published_at DESC, p.id DESC
+LIMIT @page_size::int;
+
+
+-- name: ListAuthorPosts :many
+SELECT * FROM posts
+WHERE author_id = $1
+ORDER BY created_at, id;
+
+
+-- name: GetPostAuthorID :one
+SELECT author_id FROM posts
+WHERE id = $1;
+
+
+-- name: GetPostSlug :one
+SELECT slug FROM posts
+WHERE id = $1;
+
+
+-- name: SavePostSlugHistory :exec
+-- Keeps the post's current slug so links to it keep working after the slug changes.
+INSERT INTO post_slug_history (slug, post_id)
+SELECT slug, id FROM posts
+WHERE id = $1
+ON CONFLICT (slug) DO NOTHING;
+
+
+-- name: DeletePostSlugHistory :exec
+DELETE FROM post_slug_history
+WHERE slug = $1 AND post_id = $2;
+
+
+-- name: UpdatePost :one
+-- Only the fields passed as non-NULL are changed.
+UPDATE posts SET
+  title = COALESCE(sqlc.narg(title), title),
+  content = COALESCE(sqlc.narg(content), content),
+  slug = COALESCE(sqlc.narg(slug), slug),
+  updated_at = now()
+WHERE id = @id
+RETURNING *;
+
+
+-- name: DeletePost :execrows
+DELETE FROM posts
+WHERE id = $1;
+
+
+-- name: TransitionPostStatus :one
+-- Moves a post to @to_status only while it is in one of @from_statuses, so two concurrent
+-- transitions cannot skip a state. Publishing stamps published_at and returning to draft clears it;
+-- any move drops a pending schedule.
+UPDATE posts SET
+  status = @to_status,
+  published_at = CASE
+    WHEN @to_status = 'published' THEN now()
+    WHEN @to_status = 'draft' THEN NULL
+    ELSE published_at
+  END,
+  publish_at = NULL,
+  updated_at = now()
+WHERE id = @id AND status = ANY(@from_statuses::text[])
+RETURNING *;
+
+
+-- name: SchedulePost :one
+-- Sets or clears the time a draft goes live. Posts that are not drafts are left alone.
+UPDATE posts SET
+  publish_at = sqlc.narg(publish_at),
+  updated_at = now()
+WHERE id = @id AND status = 'draft'
+RETURNING *;
+
+
+-- name: PublishDuePosts :many
+-- Publishes up to @batch_size drafts whose publish time has passed, including ones missed while
+-- no scheduler was running. SKIP LOCKED lets several replicas run this at once without waiting
+-- on or publishing the same rows.
+WITH due AS (
+  SELECT id FROM posts
+  WHERE status = 'draft' AND publish_at <= now()
+  ORDER BY publish_at
+  LIMIT @batch_size
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE posts p SET
+  status = 'published',
+  published_at = now(),
+  publish_at = NULL,
+  updated_at = now()
+FROM due
+WHERE p.id = due.id
+RETURNING p.id;
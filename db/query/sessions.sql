@@ -0,0 +1,28 @@
+-- name: CreateSession :one
+INSERT INTO sessions (id, user_id, user_agent, ip)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetSession :one
+SELECT * FROM sessions
+WHERE id = $1;
+
+-- name: ListUserSessions :many
+-- Sessions idle for longer than the refresh token lifetime can no longer be resumed and are hidden.
+SELECT * FROM sessions
+WHERE user_id = $1 AND revoked_at IS NULL AND last_seen_at > $2
+ORDER BY last_seen_at DESC;
+
+-- name: TouchSession :exec
+-- Updates last_seen_at at most once a minute to avoid a write on every request.
+UPDATE sessions SET last_seen_at = now(), ip = $2, user_agent = $3
+WHERE id = $1 AND last_seen_at < now() - INTERVAL '1 minute';
+
+-- name: RevokeSession :execrows
+UPDATE sessions SET revoked_at = now()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :many
+UPDATE sessions SET revoked_at = now()
+WHERE user_id = $1 AND revoked_at IS NULL
+RETURNING id;
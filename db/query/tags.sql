@@ -0,0 +1,33 @@
+-- name: UpsertTags :exec
+INSERT INTO tags (name)
+SELECT unnest(@names::text[])
+ON CONFLICT (name) DO NOTHING;
+
+-- name: AddPostTags :exec
+INSERT INTO post_tags (post_id, tag_id)
+SELECT @post_id, id FROM tags
+WHERE name = ANY(@names::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: ListPostTags :many
+SELECT t.name
+FROM post_tags pt
+JOIN tags t ON t.id = pt.tag_id
+WHERE pt.post_id = $1
+ORDER BY t.name;
+
+-- name: RemovePostTagsNotIn :exec
+-- Drops the post's tags that are not in @names; AddPostTags adds the missing ones.
+DELETE FROM post_tags pt
+USING tags t
+WHERE t.id = pt.tag_id AND pt.post_id = @post_id AND NOT (t.name = ANY(@names::text[]));
+
+-- name: ListTags :many
+-- Counts only published posts, so tags used solely on drafts or archived posts are left out.
+SELECT t.name, COUNT(*) AS post_count
+FROM tags t
+JOIN post_tags pt ON pt.tag_id = t.id
+JOIN posts p ON p.id = pt.post_id AND p.status = 'published'
+GROUP BY t.id, t.name
+ORDER BY post_count DESC, t.name
+LIMIT $1 OFFSET $2;
@@ -1,7 +1,7 @@
 
 -- name: CreateUser :one
-INSERT INTO users (email, username, password_hash)
-VALUES ($1, $2, $3)
+INSERT INTO users (email, username, password_hash, is_active)
+VALUES ($1, $2, $3, FALSE)
 RETURNING *;
 
 -- name: GetByEmail :one
@@ -14,6 +14,232 @@ SELECT
   u.is_active,
   u.token_invalid_before,
   u.created_at,
-  u.updated_at
+  u.updated_at,
+  u.email_verified_at,
+  u.verification_sent_at,
+  u.totp_secret,
+  u.totp_enabled_at,
+  u.totp_last_step,
+  u.role,
+  u.display_name,
+  u.bio,
+  u.website,
+  u.social_links
 FROM users u
 WHERE u.email = $1 AND u.is_active = TRUE;
+
+-- name: GetByID :one
+SELECT
+  u.id,
+  u.email,
+  u.username,
+  u.password_hash,
+  u.is_active,
+  u.token_invalid_before,
+  u.created_at,
+  u.updated_at,
+  u.email_verified_at,
+  u.verification_sent_at,
+  u.totp_secret,
+  u.totp_enabled_at,
+  u.totp_last_step,
+  u.role,
+  u.display_name,
+  u.bio,
+  u.website,
+  u.social_links
+FROM users u
+WHERE u.id = $1 AND u.is_active = TRUE;
+
+-- name: GetTokenInvalidBefore :one
+SELECT u.token_invalid_before
+FROM users u
+WHERE u.id = $1 AND u.is_active = TRUE;
+
+-- name: InvalidateUserTokens :execrows
+-- Moves the cutoff forward so every token issued before @cutoff is rejected. The caller picks
+-- the cutoff from the same clock that stamps token iat claims.
+UPDATE users SET token_invalid_before = @cutoff, updated_at = now()
+WHERE id = @id;
+
+-- name: UpdatePasswordHash :exec
+UPDATE users SET password_hash = $2, updated_at = now()
+WHERE id = $1;
+
+-- name: GetAnyByEmail :one
+-- Unlike GetByEmail this also returns inactive accounts so sign-in can explain why they are blocked.
+SELECT
+  u.id,
+  u.email,
+  u.username,
+  u.password_hash,
+  u.is_active,
+  u.token_invalid_before,
+  u.created_at,
+  u.updated_at,
+  u.email_verified_at,
+  u.verification_sent_at,
+  u.totp_secret,
+  u.totp_enabled_at,
+  u.totp_last_step,
+  u.role,
+  u.display_name,
+  u.bio,
+  u.website,
+  u.social_links
+FROM users u
+WHERE u.email = $1;
+
+-- name: VerifyEmail :execrows
+UPDATE users SET is_active = TRUE, email_verified_at = now(), updated_at = now()
+WHERE id = $1 AND email = $2 AND email_verified_at IS NULL;
+
+-- name: MarkVerificationSent :execrows
+-- Claims the resend slot only when the previous email is older than the given cutoff.
+UPDATE users SET verification_sent_at = now()
+WHERE id = $1
+  AND email_verified_at IS NULL
+  AND (verification_sent_at IS NULL OR verification_sent_at < $2);
+
+-- name: ReleaseVerificationSent :exec
+-- Gives back a slot claimed by MarkVerificationSent when the email could not be sent. No one else
+-- can claim the slot while it is held, so clearing it never undoes another send.
+UPDATE users SET verification_sent_at = NULL
+WHERE id = $1 AND email_verified_at IS NULL;
+
+-- name: SetTOTPSecret :execrows
+-- Stores a pending secret; it only takes effect once EnableTOTP confirms a code.
+UPDATE users SET totp_secret = $2, updated_at = now()
+WHERE id = $1 AND totp_enabled_at IS NULL;
+
+-- name: EnableTOTP :execrows
+UPDATE users SET totp_enabled_at = now(), totp_last_step = $2, updated_at = now()
+WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL;
+
+-- name: DisableTOTP :exec
+UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0, updated_at = now()
+WHERE id = $1;
+
+-- name: UseTOTPStep :execrows
+-- Accepts each TOTP time step at most once so an observed code cannot be replayed.
+UPDATE users SET totp_last_step = $2
+WHERE id = $1 AND totp_last_step < $2;
+
+-- name: ListUsers :many
+SELECT
+  u.id,
+  u.email,
+  u.username,
+  u.password_hash,
+  u.is_active,
+  u.token_invalid_before,
+  u.created_at,
+  u.updated_at,
+  u.email_verified_at,
+  u.verification_sent_at,
+  u.totp_secret,
+  u.totp_enabled_at,
+  u.totp_last_step,
+  u.role,
+  u.display_name,
+  u.bio,
+  u.website,
+  u.social_links
+FROM users u
+ORDER BY u.id
+LIMIT $1 OFFSET $2;
+
+-- name: DeactivateUser :execrows
+UPDATE users SET is_active = FALSE, updated_at = now()
+WHERE id = $1 AND is_active = TRUE;
+
+-- name: ReactivateUser :execrows
+UPDATE users SET is_active = TRUE, updated_at = now()
+WHERE id = $1 AND is_active = FALSE;
+
+-- name: SetUserRole :execrows
+UPDATE users SET role = $2, updated_at = now()
+WHERE id = $1;
+
+-- name: GetAnyByID :one
+SELECT
+  u.id,
+  u.email,
+  u.username,
+  u.password_hash,
+  u.is_active,
+  u.token_invalid_before,
+  u.created_at,
+  u.updated_at,
+  u.email_verified_at,
+  u.verification_sent_at,
+  u.totp_secret,
+  u.totp_enabled_at,
+  u.totp_last_step,
+  u.role,
+  u.display_name,
+  u.bio,
+  u.website,
+  u.social_links
+FROM users u
+WHERE u.id = $1;
+
+-- name: UpdateProfile :execrows
+-- Only the fields passed as non-NULL are changed.
+UPDATE users SET
+  username = COALESCE(sqlc.narg(username), username),
+  display_name = COALESCE(sqlc.narg(display_name), display_name),
+  bio = COALESCE(sqlc.narg(bio), bio),
+  website = COALESCE(sqlc.narg(website), website),
+  social_links = COALESCE(sqlc.narg(social_links), social_links),
+  updated_at = now()
+WHERE id = @id AND is_active = TRUE;
+
+-- name: UpdateEmail :execrows
+-- Applies a confirmed email change only if the address has not changed since the link was sent.
+UPDATE users SET email = @new_email, email_verified_at = now(), updated_at = now()
+WHERE id = @id AND email = @current_email AND is_active = TRUE;
+
+-- name: GetPublicProfile :one
+SELECT
+  u.id,
+  u.username,
+  u.display_name,
+  u.bio,
+  u.website,
+  u.social_links,
+  u.created_at,
+  (SELECT count(*) FROM posts p WHERE p.author_id = u.id AND p.status = 'published') AS post_count,
+  (SELECT count(*) FROM follows f JOIN users fu ON fu.id = f.follower_id
+    WHERE f.followee_id = u.id AND fu.is_active = TRUE) AS follower_count,
+  (SELECT count(*) FROM follows f JOIN users fu ON fu.id = f.followee_id
+    WHERE f.follower_id = u.id AND fu.is_active = TRUE) AS following_count
+FROM users u
+WHERE u.username = @username AND u.is_active = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = u.id AND b.blocked_id = @viewer_id
+  );
+
+-- name: GetIDByUsername :one
+SELECT u.id FROM users u
+WHERE u.username = $1 AND u.is_active = TRUE;
+
+-- name: GetVisibleIDByUsername :one
+-- Resolves an active user the viewer may see, that is one who has not blocked the viewer.
+SELECT u.id FROM users u
+WHERE u.username = @username AND u.is_active = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = u.id AND b.blocked_id = @viewer_id
+  );
+
+-- name: GetAnyIDByUsername :one
+-- Resolves a username whether or not the account is still active.
+SELECT u.id FROM users u
+WHERE u.username = $1;
+
+-- name: RehashPassword :exec
+-- Swaps in an upgraded hash only if the password was not changed in the meantime.
+UPDATE users SET password_hash = @new_hash
+WHERE id = @id AND password_hash = @old_hash;
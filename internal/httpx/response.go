@@ -9,6 +9,11 @@ type errorResponse struct {
 	Error string `json:"error"`
 }
 
+type fieldErrorResponse struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields"`
+}
+
 // WriteJSON encodes data as JSON and writes it with the given status code.
 func WriteJSON(w http.ResponseWriter, status int, data any) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -28,3 +33,11 @@ func WriteError(w http.ResponseWriter, status int, err error) {
 		Error: err.Error(),
 	})
 }
+
+// WriteFieldErrors writes a JSON error response that also maps request fields to what is wrong with them.
+func WriteFieldErrors(w http.ResponseWriter, status int, err error, fields map[string]string) {
+	WriteJSON(w, status, fieldErrorResponse{
+		Error:  err.Error(),
+		Fields: fields,
+	})
+}
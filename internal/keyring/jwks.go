@@ -0,0 +1,64 @@
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"sort"
+
+	"github.com/OnatArslan/devlog/internal/httpx"
+)
+
+// JWK is the public JSON Web Key representation (RFC 7517) of a verification key.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// Ed25519 (RFC 8037)
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	// RSA (RFC 7518)
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every key, including retiring keys that still verify tokens.
+func (kr *Keyring) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(kr.keys))}
+	for _, k := range kr.keys {
+		jwk := JWK{
+			KeyID:     k.id,
+			Use:       "sig",
+			Algorithm: k.method.Alg(),
+		}
+		switch pub := k.public.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	// Keep the output stable so caches and diffs are not confused by map ordering.
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
+
+// ServeJWKS publishes the key set so other services can verify devlog tokens.
+func (kr *Keyring) ServeJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	httpx.WriteJSON(w, http.StatusOK, kr.JWKS())
+}
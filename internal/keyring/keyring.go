@@ -0,0 +1,219 @@
+// Package keyring holds the asymmetric keys used to sign and verify access tokens.
+//
+// One key signs new tokens while any number of additional keys stay valid for verification,
+// so keys can be rotated without logging everyone out: add the new key, make it active once
+// every replica has it, and delete the old key after the longest token lifetime has passed.
+package keyring
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Errors returned while loading keys or verifying tokens.
+var (
+	ErrNoSigningKey      = errors.New("keyring: active signing key not found")
+	ErrUnknownKey        = errors.New("keyring: unknown key id")
+	ErrUnsupportedKey    = errors.New("keyring: unsupported key type")
+	ErrAlgorithmMismatch = errors.New("keyring: token algorithm does not match key")
+	ErrVerificationOnly  = errors.New("keyring: active key has no private part")
+	ErrWeakSecret        = errors.New("keyring: secret must be at least 32 bytes")
+)
+
+const (
+	minRSAKeyBits   = 2048
+	minSecretLength = 32
+	ephemeralKeyID  = "ephemeral"
+	secretKeyInfo   = "devlog jwt signing key"
+)
+
+// key is a single verification key, optionally with the private half needed for signing.
+type key struct {
+	id      string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// Keyring signs tokens with its active key and verifies tokens signed by any of its keys.
+type Keyring struct {
+	active *key
+	keys   map[string]*key
+}
+
+// LoadDir reads every *.pem file in dir as a key whose ID is the file name without extension.
+// Files may hold PKCS#8 or PKCS#1 private keys, or PKIX public keys for verification-only keys
+// that are being retired. activeKID names the key used for signing and must have a private part.
+func LoadDir(dir, activeKID string) (*Keyring, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("keyring: %w", err)
+	}
+
+	kr := &Keyring{keys: make(map[string]*key, len(paths))}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: %w", err)
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+		k, err := parsePEM(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: %s: %w", path, err)
+		}
+		kr.keys[kid] = k
+	}
+
+	active, ok := kr.keys[activeKID]
+	if !ok {
+		return nil, ErrNoSigningKey
+	}
+	if active.private == nil {
+		return nil, ErrVerificationOnly
+	}
+	kr.active = active
+	return kr, nil
+}
+
+// Ephemeral returns a keyring holding a freshly generated Ed25519 key. Tokens it signs stop
+// verifying when the process restarts, so it is only suitable for local development.
+func Ephemeral() (*Keyring, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("keyring: %w", err)
+	}
+
+	k := &key{
+		id:      ephemeralKeyID,
+		method:  jwt.SigningMethodEdDSA,
+		private: priv,
+		public:  pub,
+	}
+	return &Keyring{
+		active: k,
+		keys:   map[string]*key{k.id: k},
+	}, nil
+}
+
+// FromSecret derives an Ed25519 key from a shared secret, so every replica started with the same
+// secret signs with the same key and tokens survive restarts. The key ID is taken from the public
+// key, so changing the secret also changes the kid. Deployments that need rotation should move
+// to LoadDir.
+func FromSecret(secret string) (*Keyring, error) {
+	if len(secret) < minSecretLength {
+		return nil, ErrWeakSecret
+	}
+
+	seed, err := hkdf.Key(sha256.New, []byte(secret), nil, secretKeyInfo, ed25519.SeedSize)
+	if err != nil {
+		return nil, fmt.Errorf("keyring: %w", err)
+	}
+	priv := ed25519.NewKeyFromSeed(seed)
+	pub := priv.Public().(ed25519.PublicKey)
+	fingerprint := sha256.Sum256(pub)
+
+	k := &key{
+		id:      "secret-" + hex.EncodeToString(fingerprint[:8]),
+		method:  jwt.SigningMethodEdDSA,
+		private: priv,
+		public:  pub,
+	}
+	return &Keyring{
+		active: k,
+		keys:   map[string]*key{k.id: k},
+	}, nil
+}
+
+// parsePEM decodes one PEM block into a key and picks the signing method from the key type.
+func parsePEM(kid string, data []byte) (*key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: PEM type %q", ErrUnsupportedKey, block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k := &key{id: kid}
+	switch v := parsed.(type) {
+	case ed25519.PrivateKey:
+		k.method, k.private, k.public = jwt.SigningMethodEdDSA, v, v.Public()
+	case ed25519.PublicKey:
+		k.method, k.public = jwt.SigningMethodEdDSA, v
+	case *rsa.PrivateKey:
+		k.method, k.private, k.public = jwt.SigningMethodRS256, v, &v.PublicKey
+	case *rsa.PublicKey:
+		k.method, k.public = jwt.SigningMethodRS256, v
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, parsed)
+	}
+
+	if pub, ok := k.public.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("%w: RSA keys must be at least %d bits", ErrUnsupportedKey, minRSAKeyBits)
+	}
+	return k, nil
+}
+
+// Sign signs claims with the active key and records its ID in the kid header.
+func (kr *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(kr.active.method, claims)
+	token.Header["kid"] = kr.active.id
+	return token.SignedString(kr.active.private)
+}
+
+// Keyfunc resolves the verification key named by a token's kid header. It is meant to be
+// passed to jwt.Parse and rejects tokens whose algorithm does not match the key.
+func (kr *Keyring) Keyfunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	k, ok := kr.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if t.Method.Alg() != k.method.Alg() {
+		return nil, ErrAlgorithmMismatch
+	}
+	return k.public, nil
+}
+
+// Algorithms lists the signing algorithms of all loaded keys, for jwt.WithValidMethods.
+func (kr *Keyring) Algorithms() []string {
+	seen := make(map[string]bool)
+	var algs []string
+	for _, k := range kr.keys {
+		if alg := k.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+	sort.Strings(algs)
+	return algs
+}
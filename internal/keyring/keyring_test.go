@@ -0,0 +1,238 @@
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writePEM stores der as a PEM block of the given type in dir/kid.pem.
+func writePEM(t *testing.T, dir, kid, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeEd25519(t *testing.T, dir, kid string) ed25519.PrivateKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, kid, "PRIVATE KEY", der)
+	return priv
+}
+
+func writeRSA(t *testing.T, dir, kid string, bits int) *rsa.PrivateKey {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, kid, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(priv))
+	return priv
+}
+
+func sign(t *testing.T, kr *Keyring) string {
+	t.Helper()
+	token, err := kr.Sign(jwt.RegisteredClaims{
+		Subject:   "42",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func verify(kr *Keyring, token string) error {
+	_, err := jwt.Parse(token, kr.Keyfunc, jwt.WithValidMethods(kr.Algorithms()))
+	return err
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	old := writeEd25519(t, dir, "2025-01")
+
+	before, err := LoadDir(dir, "2025-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken := sign(t, before)
+
+	// Step 1: the new key is added and made active; old tokens keep verifying.
+	writeRSA(t, dir, "2025-06", 2048)
+	during, err := LoadDir(dir, "2025-06")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken := sign(t, during)
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if err := verify(during, token); err != nil {
+			t.Errorf("%s token rejected during rotation: %v", name, err)
+		}
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != "2025-06" || parsed.Method.Alg() != "RS256" {
+		t.Errorf("new token has kid %v and alg %s, want 2025-06 and RS256", parsed.Header["kid"], parsed.Method.Alg())
+	}
+	if got := during.Algorithms(); !slices.Equal(got, []string{"EdDSA", "RS256"}) {
+		t.Errorf("Algorithms = %v, want [EdDSA RS256]", got)
+	}
+
+	// Step 2: the old key is kept for verification only and can no longer sign.
+	pubDER, err := x509.MarshalPKIXPublicKey(old.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, "2025-01", "PUBLIC KEY", pubDER)
+	retiring, err := LoadDir(dir, "2025-06")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(retiring, oldToken); err != nil {
+		t.Errorf("old token rejected by its verification-only key: %v", err)
+	}
+	if _, err := LoadDir(dir, "2025-01"); !errors.Is(err, ErrVerificationOnly) {
+		t.Errorf("LoadDir with a public-only active key error = %v, want ErrVerificationOnly", err)
+	}
+
+	// Step 3: the old key is deleted and its tokens stop verifying.
+	if err := os.Remove(filepath.Join(dir, "2025-01.pem")); err != nil {
+		t.Fatal(err)
+	}
+	after, err := LoadDir(dir, "2025-06")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(after, oldToken); err == nil {
+		t.Error("old token still verifies after its key was removed")
+	}
+	if err := verify(after, newToken); err != nil {
+		t.Errorf("new token rejected after rotation: %v", err)
+	}
+}
+
+func TestLoadDirErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, dir string)
+		active  string
+		wantErr error
+	}{
+		{
+			name:    "missing active key",
+			setup:   func(t *testing.T, dir string) { writeEd25519(t, dir, "a") },
+			active:  "b",
+			wantErr: ErrNoSigningKey,
+		},
+		{
+			name:    "short RSA key",
+			setup:   func(t *testing.T, dir string) { writeRSA(t, dir, "a", 1024) },
+			active:  "a",
+			wantErr: ErrUnsupportedKey,
+		},
+		{
+			name:    "unknown PEM type",
+			setup:   func(t *testing.T, dir string) { writePEM(t, dir, "a", "CERTIFICATE", []byte("x")) },
+			active:  "a",
+			wantErr: ErrUnsupportedKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.setup(t, dir)
+			if _, err := LoadDir(dir, tt.active); !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadDir error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyfuncRejectsAlgorithmMismatch(t *testing.T) {
+	dir := t.TempDir()
+	writeRSA(t, dir, "rsa", 2048)
+	kr, err := LoadDir(dir, "rsa")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := &jwt.Token{Method: jwt.SigningMethodEdDSA, Header: map[string]any{"kid": "rsa"}}
+	if _, err := kr.Keyfunc(token); !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Errorf("Keyfunc error = %v, want ErrAlgorithmMismatch", err)
+	}
+}
+
+func TestFromSecret(t *testing.T) {
+	secret := strings.Repeat("s", minSecretLength)
+
+	a, err := FromSecret(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := FromSecret(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(b, sign(t, a)); err != nil {
+		t.Errorf("token from one replica rejected by another with the same secret: %v", err)
+	}
+
+	other, err := FromSecret(secret + "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.active.id == a.active.id {
+		t.Errorf("different secrets share key ID %q", a.active.id)
+	}
+	if err := verify(other, sign(t, a)); err == nil {
+		t.Error("token verified with a key derived from another secret")
+	}
+
+	if _, err := FromSecret(secret[1:]); !errors.Is(err, ErrWeakSecret) {
+		t.Errorf("FromSecret with a short secret error = %v, want ErrWeakSecret", err)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	dir := t.TempDir()
+	writeRSA(t, dir, "b-rsa", 2048)
+	writeEd25519(t, dir, "a-ed")
+	kr, err := LoadDir(dir, "a-ed")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := kr.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(set.Keys))
+	}
+	ed, rsaKey := set.Keys[0], set.Keys[1]
+	if ed.KeyID != "a-ed" || ed.KeyType != "OKP" || ed.Curve != "Ed25519" || ed.Algorithm != "EdDSA" || ed.X == "" {
+		t.Errorf("Ed25519 JWK = %+v", ed)
+	}
+	if rsaKey.KeyID != "b-rsa" || rsaKey.KeyType != "RSA" || rsaKey.Algorithm != "RS256" || rsaKey.N == "" || rsaKey.E != "AQAB" {
+		t.Errorf("RSA JWK = %+v", rsaKey)
+	}
+}
@@ -0,0 +1,16 @@
+// Package mailer defines how the application sends transactional email.
+package mailer
+
+import "context"
+
+// Message is a plain-text email addressed to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional email. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
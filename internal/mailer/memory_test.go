@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestMemoryMailer(t *testing.T) {
+	m := NewMemoryMailer()
+	if _, ok := m.Last(); ok {
+		t.Error("Last on an empty mailer reported a message")
+	}
+
+	msgs := []Message{
+		{To: "a@example.com", Subject: "first", Body: "1"},
+		{To: "b@example.com", Subject: "second", Body: "2"},
+	}
+	for _, msg := range msgs {
+		if err := m.Send(context.Background(), msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := m.Messages()
+	if len(got) != len(msgs) {
+		t.Fatalf("Messages returned %d messages, want %d", len(got), len(msgs))
+	}
+	for i := range msgs {
+		if got[i] != msgs[i] {
+			t.Errorf("Messages()[%d] = %+v, want %+v", i, got[i], msgs[i])
+		}
+	}
+	if last, ok := m.Last(); !ok || last != msgs[1] {
+		t.Errorf("Last = %+v, %v, want %+v", last, ok, msgs[1])
+	}
+
+	// The returned slice is a copy.
+	got[0].Subject = "changed"
+	if m.Messages()[0].Subject != "first" {
+		t.Error("changing the result of Messages changed the stored message")
+	}
+}
+
+func TestMemoryMailerConcurrentSend(t *testing.T) {
+	m := NewMemoryMailer()
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = m.Send(context.Background(), Message{To: fmt.Sprintf("%d@example.com", i)})
+		}()
+	}
+	wg.Wait()
+
+	if n := len(m.Messages()); n != 50 {
+		t.Errorf("got %d messages after 50 concurrent sends, want 50", n)
+	}
+}
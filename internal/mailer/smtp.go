@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPMailer sends email through an SMTP relay using PLAIN authentication when credentials are set.
+type SMTPMailer struct {
+	addr     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer creates a mailer for the relay at addr (host:port) sending as from.
+func NewSMTPMailer(addr, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		addr:     addr,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Send delivers msg through the configured relay.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Only authenticate when credentials are configured; local relays often accept anonymous mail.
+	var auth smtp.Auth
+	if m.username != "" {
+		host, _, err := net.SplitHostPort(m.addr)
+		if err != nil {
+			return fmt.Errorf("smtp mailer: %w", err)
+		}
+		auth = smtp.PlainAuth("", m.username, m.password, host)
+	}
+
+	if err := smtp.SendMail(m.addr, auth, m.from, []string{msg.To}, m.build(msg)); err != nil {
+		return fmt.Errorf("smtp mailer: %w", err)
+	}
+	return nil
+}
+
+// build renders msg as an RFC 5322 message with CRLF line endings.
+func (m *SMTPMailer) build(msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + m.from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
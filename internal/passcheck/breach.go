@@ -0,0 +1,136 @@
+// Package passcheck screens candidate passwords against known breaches and estimates how easy
+// they are to guess.
+package passcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// BreachChecker reports whether a password is known to have appeared in a data breach.
+type BreachChecker interface {
+	Breached(ctx context.Context, password string) (bool, error)
+}
+
+// maxLineLength bounds one "<40 hex SHA-1>:<count>" line including a CRLF terminator.
+const maxLineLength = 64
+
+// FileBreachChecker looks passwords up in a local copy of the Have I Been Pwned SHA-1 list,
+// downloaded "ordered by hash": one uppercase "<sha1>:<count>" entry per line, sorted by hash.
+// The file is binary searched in place, so the multi-gigabyte list is never loaded into memory.
+type FileBreachChecker struct {
+	f    *os.File
+	size int64
+}
+
+// OpenFileBreachChecker opens the sorted hash file at path.
+func OpenFileBreachChecker(path string) (*FileBreachChecker, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("passcheck: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("passcheck: %w", err)
+	}
+	return &FileBreachChecker{f: f, size: info.Size()}, nil
+}
+
+// Close releases the underlying file.
+func (c *FileBreachChecker) Close() error {
+	return c.f.Close()
+}
+
+// Breached hashes password with SHA-1 and binary searches the file for it.
+func (c *FileBreachChecker) Breached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	target := []byte(strings.ToUpper(hex.EncodeToString(sum[:])))
+
+	// Invariant: every line starting before lo sorts below target, and every line starting at
+	// or after hi sorts at or above it. Offsets are byte positions, not line numbers, so each
+	// probe first skips forward to the start of the next full line.
+	lo, hi := int64(0), c.size
+	for lo < hi {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		mid := lo + (hi-lo)/2
+		start, line, err := c.lineAfter(mid)
+		if err != nil {
+			return false, err
+		}
+		if start >= hi {
+			// No line begins in [mid, hi); the answer, if any, is at or before mid.
+			hi = mid
+			continue
+		}
+
+		switch cmp := bytes.Compare(lineHash(line), target); {
+		case cmp == 0:
+			return true, nil
+		case cmp < 0:
+			lo = start + int64(len(line))
+		default:
+			hi = mid
+		}
+	}
+
+	// lo now sits on the first line that could equal target.
+	start, line, err := c.lineAfter(lo)
+	if err != nil {
+		return false, err
+	}
+	return start < c.size && bytes.Equal(lineHash(line), target), nil
+}
+
+// lineAfter returns the first line that starts at or after off, together with its offset.
+// The returned line includes its terminating newline, if any.
+func (c *FileBreachChecker) lineAfter(off int64) (int64, []byte, error) {
+	if off >= c.size {
+		return c.size, nil, nil
+	}
+
+	// A line starts at off if off is the beginning of the file or follows a newline.
+	start := off
+	if off > 0 {
+		start = off - 1
+	}
+
+	buf := make([]byte, 2*maxLineLength)
+	n, err := c.f.ReadAt(buf, start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, nil, fmt.Errorf("passcheck: %w", err)
+	}
+	buf = buf[:n]
+
+	if off > 0 {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			return c.size, nil, nil
+		}
+		buf = buf[i+1:]
+		start += int64(i + 1)
+	}
+
+	if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+		buf = buf[:i+1]
+	}
+	return start, buf, nil
+}
+
+// lineHash returns the hash part of a "<hash>:<count>" line.
+func lineHash(line []byte) []byte {
+	if i := bytes.IndexByte(line, ':'); i >= 0 {
+		return line[:i]
+	}
+	return bytes.TrimRight(line, "\r\n")
+}
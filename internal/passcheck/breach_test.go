@@ -0,0 +1,120 @@
+package passcheck
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeHashFile writes the SHA-1 of each password in the HIBP "ordered by hash" format.
+func writeHashFile(t *testing.T, passwords []string, eol string) string {
+	t.Helper()
+
+	var lines []string
+	for i, p := range passwords {
+		sum := sha1.Sum([]byte(p))
+		lines = append(lines, fmt.Sprintf("%s:%d", strings.ToUpper(hex.EncodeToString(sum[:])), i+1))
+	}
+	slices.Sort(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, eol)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFileBreachChecker(t *testing.T) {
+	var breached []string
+	for i := range 500 {
+		breached = append(breached, fmt.Sprintf("password%d", i))
+	}
+
+	for _, eol := range []string{"\n", "\r\n"} {
+		checker, err := OpenFileBreachChecker(writeHashFile(t, breached, eol))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer checker.Close()
+
+		tests := []struct {
+			password string
+			want     bool
+		}{
+			{"password0", true},
+			{"password250", true},
+			{"password499", true},
+			{"password500", false},
+			{"", false},
+			{"correct horse battery staple", false},
+		}
+		for _, tt := range tests {
+			got, err := checker.Breached(context.Background(), tt.password)
+			if err != nil {
+				t.Fatalf("Breached(%q) with %q endings: %v", tt.password, eol, err)
+			}
+			if got != tt.want {
+				t.Errorf("Breached(%q) with %q endings = %v, want %v", tt.password, eol, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestFileBreachCheckerFindsEveryEntry(t *testing.T) {
+	// Every line must be reachable, including the first and last and those next to probe points.
+	var breached []string
+	for i := range 64 {
+		breached = append(breached, fmt.Sprintf("p%d", i))
+	}
+	checker, err := OpenFileBreachChecker(writeHashFile(t, breached, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checker.Close()
+
+	for _, p := range breached {
+		got, err := checker.Breached(context.Background(), p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got {
+			t.Errorf("Breached(%q) = false, want true", p)
+		}
+	}
+}
+
+func TestFileBreachCheckerEmptyFile(t *testing.T) {
+	checker, err := OpenFileBreachChecker(writeHashFile(t, nil, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checker.Close()
+
+	got, err := checker.Breached(context.Background(), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got {
+		t.Error("Breached on an empty list = true, want false")
+	}
+}
+
+func TestFileBreachCheckerCancelled(t *testing.T) {
+	checker, err := OpenFileBreachChecker(writeHashFile(t, []string{"a", "b", "c"}, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checker.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := checker.Breached(ctx, "a"); err == nil {
+		t.Error("Breached with a cancelled context returned no error")
+	}
+}
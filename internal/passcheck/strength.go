@@ -0,0 +1,473 @@
+package passcheck
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Strength is the outcome of estimating how many guesses an attacker needs for a password.
+// It follows the approach of zxcvbn: the password is split into the cheapest sequence of
+// recognisable patterns and the guesses for each pattern are multiplied together.
+type Strength struct {
+	// Score runs from 0 (trivial to guess) to 4 (very hard to guess).
+	Score   int
+	Guesses float64
+	// Warning explains the weakest pattern found, if any.
+	Warning string
+}
+
+// Score thresholds in guesses, as used by zxcvbn.
+var scoreThresholds = [...]float64{1e3, 1e6, 1e8, 1e10}
+
+// Warnings attached to the weakest pattern in a password.
+const (
+	warnCommon   = "this is a very common password"
+	warnWord     = "a single dictionary word is easy to guess"
+	warnPersonal = "avoid using your name or email address"
+	warnSequence = "sequences like abc or 6543 are easy to guess"
+	warnRepeat   = "repeats like aaa or abcabc are easy to guess"
+	warnKeyboard = "straight rows of keys are easy to guess"
+	warnYear     = "years are easy to guess"
+)
+
+// match is a recognised pattern covering password runes [i, j].
+type match struct {
+	i, j    int
+	guesses float64
+	warning string
+}
+
+// maxAnalysedRunes caps how much of a password is matched against patterns. The matching is
+// polynomial in the length and runs before sign-up is throttled, so longer input would let
+// anyone burn CPU. Runes past the cap are ignored, which can only underestimate the strength.
+const maxAnalysedRunes = 40
+
+// Estimate scores password. userInputs such as the username and email are treated as the most
+// likely dictionary words, because attackers try them first. Only the first maxAnalysedRunes
+// runes are analysed.
+func Estimate(password string, userInputs ...string) Strength {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return Strength{}
+	}
+	if len(runes) > maxAnalysedRunes {
+		runes = runes[:maxAnalysedRunes]
+	}
+
+	personal := make(map[string]int)
+	for _, input := range userInputs {
+		for _, word := range splitWords(strings.ToLower(input)) {
+			if len([]rune(word)) >= 3 {
+				personal[word] = 1
+			}
+		}
+	}
+
+	guesses, used := analyse(runes, personal, make(map[string]float64))
+
+	strength := Strength{Guesses: guesses, Score: len(scoreThresholds)}
+	for score, threshold := range scoreThresholds {
+		if guesses < threshold {
+			strength.Score = score
+			break
+		}
+	}
+
+	// Report the pattern that contributed the fewest guesses per character.
+	best := math.Inf(1)
+	for _, m := range used {
+		if m.warning == "" {
+			continue
+		}
+		if perChar := math.Log10(m.guesses) / float64(m.j-m.i+1); perChar < best {
+			best = perChar
+			strength.Warning = m.warning
+		}
+	}
+	return strength
+}
+
+// analyse finds every pattern in runes and returns the guesses of the cheapest cover along with
+// the matches it uses. memo caches the guesses for repeated chunks across recursive calls.
+func analyse(runes []rune, personal map[string]int, memo map[string]float64) (float64, []match) {
+	var matches []match
+	matches = append(matches, dictionaryMatches(runes, personal)...)
+	matches = append(matches, sequenceMatches(runes)...)
+	matches = append(matches, repeatMatches(runes, personal, memo)...)
+	matches = append(matches, keyboardMatches(runes)...)
+	matches = append(matches, yearMatches(runes)...)
+
+	return minimumGuesses(runes, matches)
+}
+
+// minimumGuesses finds the cheapest way to cover the password with matches, filling gaps with
+// brute force. Like zxcvbn it multiplies guesses and adds a factorial term for the number of
+// patterns, so one long match is cheaper than many short ones of the same total guesses.
+func minimumGuesses(runes []rune, matches []match) (float64, []match) {
+	n := len(runes)
+	byEnd := make([][]match, n)
+	for _, m := range matches {
+		byEnd[m.j] = append(byEnd[m.j], m)
+	}
+
+	// best[k][j] is the lowest product of guesses covering runes[:j+1] with exactly k+1 patterns.
+	type cell struct {
+		product float64
+		prev    int // end index of the previous pattern, or -1
+		m       match
+		ok      bool
+	}
+	best := make([][]cell, n)
+	for k := range best {
+		best[k] = make([]cell, n)
+	}
+
+	consider := func(k int, m match) {
+		product := m.guesses
+		prev := m.i - 1
+		if k > 0 {
+			if prev < 0 || !best[k-1][prev].ok {
+				return
+			}
+			product *= best[k-1][prev].product
+		} else if prev >= 0 {
+			return
+		}
+		if c := best[k][m.j]; !c.ok || product < c.product {
+			best[k][m.j] = cell{product: product, prev: prev, m: m, ok: true}
+		}
+	}
+
+	for j := 0; j < n; j++ {
+		for k := 0; k <= j; k++ {
+			for _, m := range byEnd[j] {
+				consider(k, m)
+			}
+			// Brute force any span ending at j. Extending an earlier brute-force span is never
+			// cheaper than starting a new one right after the previous pattern, so every start works.
+			for i := 0; i <= j; i++ {
+				consider(k, match{i: i, j: j, guesses: bruteforceGuesses(j - i + 1)})
+			}
+		}
+	}
+
+	total, bestK := math.Inf(1), -1
+	for k := 0; k < n; k++ {
+		c := best[k][n-1]
+		if !c.ok {
+			continue
+		}
+		if g := factorial(k+1)*c.product + math.Pow(10000, float64(k)); g < total {
+			total, bestK = g, k
+		}
+	}
+
+	var used []match
+	for k, j := bestK, n-1; k >= 0 && j >= 0; k-- {
+		c := best[k][j]
+		used = append(used, c.m)
+		j = c.prev
+	}
+	return total, used
+}
+
+// bruteforceGuesses is the cost of guessing length characters with no known structure.
+func bruteforceGuesses(length int) float64 {
+	g := math.Pow(10, float64(length))
+	if length == 1 {
+		g++
+	}
+	return g
+}
+
+func factorial(n int) float64 {
+	f := 1.0
+	for i := 2; i <= n; i++ {
+		f *= float64(i)
+	}
+	return f
+}
+
+// leet maps common character substitutions back to the letter they stand for.
+var leet = map[rune]rune{
+	'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '1': 'i', '!': 'i',
+	'|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z',
+}
+
+// dictionaryMatches finds common passwords, common words and personal inputs, including
+// capitalised, reversed and l33t-spelled variants.
+func dictionaryMatches(runes []rune, personal map[string]int) []match {
+	lower := []rune(strings.ToLower(string(runes)))
+	unleet := make([]rune, len(lower))
+	for i, r := range lower {
+		if sub, ok := leet[r]; ok {
+			unleet[i] = sub
+		} else {
+			unleet[i] = r
+		}
+	}
+
+	var matches []match
+	for i := 0; i < len(runes); i++ {
+		for j := i + 2; j < len(runes); j++ {
+			for _, variant := range []struct {
+				word  []rune
+				extra float64
+			}{
+				{lower[i : j+1], 1},
+				{unleet[i : j+1], 2},
+			} {
+				if variant.extra > 1 && string(variant.word) == string(lower[i:j+1]) {
+					continue
+				}
+				word := string(variant.word)
+				reversed := reverse(word)
+				for _, candidate := range []struct {
+					word  string
+					extra float64
+				}{
+					{word, variant.extra},
+					{reversed, variant.extra * 2},
+				} {
+					rank, warning := lookup(candidate.word, personal)
+					if rank == 0 {
+						continue
+					}
+					matches = append(matches, match{
+						i:       i,
+						j:       j,
+						guesses: float64(rank) * candidate.extra * uppercaseVariations(runes[i:j+1]),
+						warning: warning,
+					})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// lookup returns the rank of word in the personal and built-in dictionaries, or 0.
+func lookup(word string, personal map[string]int) (int, string) {
+	if rank, ok := personal[word]; ok {
+		return rank, warnPersonal
+	}
+	if rank, ok := commonPasswords[word]; ok {
+		return rank, warnCommon
+	}
+	if rank, ok := commonWords[word]; ok {
+		return rank, warnWord
+	}
+	return 0, ""
+}
+
+// uppercaseVariations estimates the extra guesses for the capitalisation of a matched word.
+// All-lowercase, all-uppercase and capitalised words only add a factor of two.
+func uppercaseVariations(word []rune) float64 {
+	var upper, lower int
+	for _, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	if lower == 0 || (upper == 1 && unicode.IsUpper(word[0])) {
+		return 2
+	}
+
+	// Any mix: count the ways to choose up to min(upper, lower) positions.
+	variations := 0.0
+	for k := 1; k <= min(upper, lower); k++ {
+		variations += binomial(upper+lower, k)
+	}
+	return variations
+}
+
+func binomial(n, k int) float64 {
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r = r * float64(n-k+i) / float64(i)
+	}
+	return r
+}
+
+// sequenceMatches finds runs of three or more characters with a constant step of one, such
+// as "abc", "987" or "xyz".
+func sequenceMatches(runes []rune) []match {
+	var matches []match
+	for i := 0; i < len(runes)-2; {
+		delta := runes[i+1] - runes[i]
+		if delta != 1 && delta != -1 {
+			i++
+			continue
+		}
+		j := i + 1
+		for j+1 < len(runes) && runes[j+1]-runes[j] == delta {
+			j++
+		}
+		if j-i >= 2 {
+			base := 26.0
+			switch first := unicode.ToLower(runes[i]); {
+			case first == 'a' || first == 'z' || first == '0' || first == '1' || first == '9':
+				base = 4
+			case unicode.IsDigit(first):
+				base = 10
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			matches = append(matches, match{i: i, j: j, guesses: base * float64(j-i+1), warning: warnSequence})
+		}
+		i = j
+	}
+	return matches
+}
+
+// repeatMatches finds a chunk repeated back to back, such as "aaa" or "abcabc". The cost is
+// the cost of the chunk itself times the number of repetitions. Like zxcvbn it scans greedily:
+// at each position it takes the longest repeat, then continues after it, so every rune is
+// covered by at most one repeat match.
+func repeatMatches(runes []rune, personal map[string]int, memo map[string]float64) []match {
+	var matches []match
+	for i := 0; i < len(runes); {
+		span := longestRepeat(runes[i:])
+		if span < 3 {
+			i++
+			continue
+		}
+		period := smallestPeriod(runes[i : i+span])
+		chunk := string(runes[i : i+period])
+		base, ok := memo[chunk]
+		if !ok {
+			base, _ = analyse(runes[i:i+period], personal, memo)
+			memo[chunk] = base
+		}
+		matches = append(matches, match{
+			i:       i,
+			j:       i + span - 1,
+			guesses: base * float64(span/period),
+			warning: warnRepeat,
+		})
+		i += span
+	}
+	return matches
+}
+
+// longestRepeat returns the length of the longest prefix of runes made of one chunk repeated at
+// least twice, or 0 if runes does not start with a repeat.
+func longestRepeat(runes []rune) int {
+	longest := 0
+	for size := 1; 2*size <= len(runes); size++ {
+		count := 1
+		for (count+1)*size <= len(runes) && equalRunes(runes[:size], runes[count*size:(count+1)*size]) {
+			count++
+		}
+		if count >= 2 && count*size > longest {
+			longest = count * size
+		}
+	}
+	return longest
+}
+
+// smallestPeriod returns the length of the shortest chunk that repeated makes up runes.
+func smallestPeriod(runes []rune) int {
+	for size := 1; size < len(runes); size++ {
+		if len(runes)%size != 0 {
+			continue
+		}
+		repeats := true
+		for k := size; k < len(runes); k += size {
+			if !equalRunes(runes[:size], runes[k:k+size]) {
+				repeats = false
+				break
+			}
+		}
+		if repeats {
+			return size
+		}
+	}
+	return len(runes)
+}
+
+func equalRunes(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// keyboardRows are runs of adjacent keys on a QWERTY keyboard.
+var keyboardRows = []string{"qwertyuiop", "asdfghjkl", "zxcvbnm", "1qaz", "2wsx", "3edc", "4rfv", "5tgb", "6yhn", "7ujm"}
+
+// keyboardMatches finds four or more keys typed along one keyboard row, in either direction.
+func keyboardMatches(runes []rune) []match {
+	lower := strings.ToLower(string(runes))
+	lowerRunes := []rune(lower)
+
+	var matches []match
+	for i := 0; i < len(lowerRunes); i++ {
+		for j := i + 3; j < len(lowerRunes); j++ {
+			s := string(lowerRunes[i : j+1])
+			for _, row := range keyboardRows {
+				if strings.Contains(row, s) || strings.Contains(row, reverse(s)) {
+					// zxcvbn's spatial estimate for a single straight run: starting keys times
+					// average neighbours for each following key.
+					guesses := 94 * 4.6 * float64(j-i) * uppercaseVariations(runes[i:j+1])
+					matches = append(matches, match{i: i, j: j, guesses: guesses, warning: warnKeyboard})
+					break
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// referenceYear anchors year guesses; recent years are the most likely.
+const referenceYear = 2026
+
+// yearMatches finds four-digit years between 1900 and 2099.
+func yearMatches(runes []rune) []match {
+	var matches []match
+	for i := 0; i+4 <= len(runes); i++ {
+		year := 0
+		for _, r := range runes[i : i+4] {
+			if r < '0' || r > '9' {
+				year = -1
+				break
+			}
+			year = year*10 + int(r-'0')
+		}
+		if year < 1900 || year > 2099 {
+			continue
+		}
+		span := math.Abs(float64(year - referenceYear))
+		matches = append(matches, match{i: i, j: i + 3, guesses: math.Max(span, 20), warning: warnYear})
+	}
+	return matches
+}
+
+// splitWords breaks user inputs such as "jane.doe@example.com" into candidate words.
+func splitWords(s string) []string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return append(words, s)
+}
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
@@ -0,0 +1,101 @@
+package passcheck
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		maxScore   int
+		minScore   int
+		warning    string
+	}{
+		{name: "empty", password: "", maxScore: 0},
+		{name: "common password", password: "password", maxScore: 0, warning: warnCommon},
+		{name: "repeat", password: "aaaaaaaaaa", maxScore: 1, warning: warnRepeat},
+		{name: "repeated chunk", password: "abcabcabc", maxScore: 1, warning: warnRepeat},
+		{name: "sequence", password: "abcdefgh", maxScore: 1, warning: warnSequence},
+		{name: "keyboard row", password: "qwertyuiop", maxScore: 1},
+		{name: "personal input", password: "janedoe", userInputs: []string{"janedoe", "jane@example.com"}, maxScore: 1, warning: warnPersonal},
+		{name: "random", password: "vT9#qL2!mX8&", minScore: 4, maxScore: 4},
+		{name: "long passphrase", password: "purple tugboat ledger anvil meadow", minScore: 4, maxScore: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Estimate(tt.password, tt.userInputs...)
+			if got.Score < tt.minScore || got.Score > tt.maxScore {
+				t.Errorf("Estimate(%q).Score = %d, want %d..%d", tt.password, got.Score, tt.minScore, tt.maxScore)
+			}
+			if tt.warning != "" && got.Warning != tt.warning {
+				t.Errorf("Estimate(%q).Warning = %q, want %q", tt.password, got.Warning, tt.warning)
+			}
+		})
+	}
+}
+
+func TestEstimateLongInputIsCheap(t *testing.T) {
+	for _, password := range []string{
+		strings.Repeat("a", 61) + "A1!",
+		strings.Repeat("ab", 5000),
+		strings.Repeat("x7$Qz", 2000),
+	} {
+		start := time.Now()
+		Estimate(password, "jane", "jane@example.com")
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("Estimate of a %d-rune password took %v", len(password), elapsed)
+		}
+	}
+}
+
+func TestEstimateIgnoresRunesPastCap(t *testing.T) {
+	prefix := strings.Repeat("a", maxAnalysedRunes)
+	if a, b := Estimate(prefix), Estimate(prefix+"vT9#qL2!mX8&"); a != b {
+		t.Errorf("Estimate changed with runes past the cap: %+v vs %+v", a, b)
+	}
+}
+
+func TestRepeatMatches(t *testing.T) {
+	tests := []struct {
+		password string
+		want     []match
+	}{
+		{"abc", nil},
+		{"aaa", []match{{i: 0, j: 2}}},
+		{"xabababy", []match{{i: 1, j: 6}}},
+		{"aaabbb", []match{{i: 0, j: 2}, {i: 3, j: 5}}},
+	}
+	for _, tt := range tests {
+		got := repeatMatches([]rune(tt.password), nil, make(map[string]float64))
+		if len(got) != len(tt.want) {
+			t.Fatalf("repeatMatches(%q) = %+v, want spans %+v", tt.password, got, tt.want)
+		}
+		for k := range got {
+			if got[k].i != tt.want[k].i || got[k].j != tt.want[k].j {
+				t.Errorf("repeatMatches(%q)[%d] covers [%d, %d], want [%d, %d]",
+					tt.password, k, got[k].i, got[k].j, tt.want[k].i, tt.want[k].j)
+			}
+		}
+	}
+}
+
+func TestSmallestPeriod(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"aaaa", 1},
+		{"abab", 2},
+		{"abcabcabc", 3},
+		{"abcd", 4},
+	}
+	for _, tt := range tests {
+		if got := smallestPeriod([]rune(tt.s)); got != tt.want {
+			t.Errorf("smallestPeriod(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
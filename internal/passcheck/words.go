@@ -0,0 +1,49 @@
+package passcheck
+
+import "strings"
+
+// Ranked word lists, most frequent first. The rank doubles as the guess count for a match, so
+// only the head of each list matters much; the long tail is covered by the breach file.
+var (
+	commonPasswords = rankWords(`
+password 123456 12345678 qwerty 123456789 12345 1234 111111 1234567 dragon
+123123 baseball abc123 football monkey letmein shadow master 696969 mustang
+michael pussy superman 1234567890 iloveyou trustno1 hello welcome admin login
+princess solo starwars passw0rd sunshine qazwsx ashley bailey access flower
+hottie loveme zaq1zaq1 charlie aa123456 donald batman jordan harley ranger
+jennifer hunter buster soccer tigger robert thomas hockey killer george
+sexy andrew michelle jessica pepper daniel asshole fuckyou 654321 joshua
+maggie biteme summer 7777777 freedom whatever nicole ginger secret matrix
+computer internet cookie test pass default changeme root guest user
+qwertyuiop asdfghjkl zxcvbnm passpass lovely snoopy cheese samsung apple
+orange banana chocolate coffee purple yellow silver golden diamond
+`)
+
+	commonWords = rankWords(`
+the of and to in is you that it he was for on are as with his they at be
+this have from or one had by word but not what all were we when your can
+said there use an each which she do how their if will up other about out
+many then them these so some her would make like him into time has look
+two more write go see number no way could people my than first water been
+call who oil its now find long down day did get come made may part love
+life world house home family friend money music game summer winter spring
+dragon tiger angel devil star moon sun sky blue red green black white dog
+cat bird fish horse lion eagle wolf bear king queen prince princess baby
+girl boy man woman school city country phone computer secret happy lucky
+magic power super master hello welcome change letmein monkey shadow sunshine
+football baseball soccer hockey basketball golf tennis blog code coder dev
+devlog admin user login pass test guest root server linux windows apple
+`)
+)
+
+// rankWords turns a whitespace-separated list into a rank lookup starting at 1.
+func rankWords(list string) map[string]int {
+	words := strings.Fields(list)
+	ranks := make(map[string]int, len(words))
+	for i, word := range words {
+		if _, ok := ranks[word]; !ok {
+			ranks[word] = i + 1
+		}
+	}
+	return ranks
+}
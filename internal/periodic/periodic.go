@@ -0,0 +1,25 @@
+// Package periodic runs background jobs, such as the post scheduler and the account purger, on a
+// fixed interval.
+package periodic
+
+import (
+	"context"
+	"time"
+)
+
+// Run calls job right away and then every interval until ctx is cancelled. A run that overlaps
+// a tick delays the next one instead of stacking up; Run never calls job concurrently.
+func Run(ctx context.Context, interval time.Duration, job func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package periodic
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRunStartsImmediatelyAndStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := 0
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(ctx, time.Hour, func(context.Context) {
+			runs++
+			cancel()
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after ctx was cancelled")
+	}
+	if runs != 1 {
+		t.Errorf("job ran %d times, want 1", runs)
+	}
+}
+
+func TestRunRepeatsEveryInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := 0
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(ctx, time.Millisecond, func(context.Context) {
+			runs++
+			if runs == 3 {
+				cancel()
+			}
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not repeat the job")
+	}
+	if runs != 3 {
+		t.Errorf("job ran %d times, want 3", runs)
+	}
+}
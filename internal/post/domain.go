@@ -6,12 +6,39 @@ import "time"
 // Status represents the publication state of a post.
 type Status string
 
-// Post is the core domain model for a blog post.
+// Publication states. New posts start as drafts, which only their author can see.
+const (
+	StatusDraft     Status = "draft"
+	StatusPublished Status = "published"
+	StatusArchived  Status = "archived"
+)
+
+// statusSources lists, for each target state, the states a post may move to it from.
+var statusSources = map[Status][]Status{
+	StatusPublished: {StatusDraft},
+	StatusArchived:  {StatusPublished},
+	StatusDraft:     {StatusPublished, StatusArchived},
+}
+
+// Post is the core domain model for a blog post. PublishedAt is zero unless the post is or was
+// published, and PublishAt is zero unless a draft is scheduled to go live. Slug is the unique,
+// human-readable name the post is addressed by in permalinks. Tags are sorted by name.
 type Post struct {
-	ID        int64
-	AuthorID  int64
-	Title     string
-	Content   string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          int64
+	AuthorID    int64
+	Title       string
+	Slug        string
+	Content     string
+	Tags        []string
+	Status      Status
+	PublishedAt time.Time
+	PublishAt   time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Tag is a topic posts are organised by, with the number of published posts carrying it.
+type Tag struct {
+	Name      string
+	PostCount int64
 }
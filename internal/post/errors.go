@@ -2,7 +2,31 @@ package post
 
 import "errors"
 
-// ErrPostNotFound is returned when a requested post does not exist.
+// Domain-level post errors shared across repository, service, and handler layers.
 var (
-	ErrPostNotFound = errors.New("post not found")
+	ErrPostNotFound    = errors.New("post not found")
+	ErrAuthorNotFound  = errors.New("author not found")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrNotPostAuthor   = errors.New("only the author can change this post")
+	ErrInvalidStatus   = errors.New("post cannot move to this status from its current one")
+	ErrInvalidSchedule = errors.New("publish time must be in the future")
+	ErrSlugTaken       = errors.New("slug is already in use")
+	ErrPostMoved       = errors.New("post has moved to a new slug")
+	ErrInvalidTag      = errors.New("tags must be 1 to 32 lowercase letters, digits or hyphens")
+	ErrTooManyTags     = errors.New("too many tags")
+	ErrInvalidTagMatch = errors.New("tag match must be any or all")
 )
+
+// MovedError reports that a slug used to belong to a post that is now addressed by Slug.
+type MovedError struct {
+	Err  error
+	Slug string
+}
+
+func (e *MovedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *MovedError) Unwrap() error {
+	return e.Err
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"path"
 	"strconv"
 	"time"
 
@@ -15,35 +16,69 @@ import (
 
 // Handler maps HTTP requests to post service operations.
 type Handler struct {
-	svc      *Service
-	validate *validator.Validate
-	authMW   func(http.Handler) http.Handler
+	svc            *Service
+	validate       *validator.Validate
+	authMW         func(http.Handler) http.Handler
+	optionalAuthMW func(http.Handler) http.Handler
 }
 
 // NewPostHandler constructs a Handler with service, validator, and auth middleware dependencies.
-func NewPostHandler(svc *Service, validate *validator.Validate, authMW func(http.Handler) http.Handler) *Handler {
+// optionalAuthMW identifies signed-in readers on public routes without rejecting anonymous ones.
+func NewPostHandler(svc *Service, validate *validator.Validate, authMW, optionalAuthMW func(http.Handler) http.Handler) *Handler {
 
 	return &Handler{
-		svc:      svc,
-		validate: validate,
-		authMW:   authMW,
+		svc:            svc,
+		validate:       validate,
+		authMW:         authMW,
+		optionalAuthMW: optionalAuthMW,
 	}
 }
 
-// CreatePostResponse is the JSON response body returned after a post is created.
+// CreatePostResponse is the JSON response body returned after a post is created or changed.
 type CreatePostResponse struct {
-	ID        int64     `json:"id"`
-	AuthorID  int64     `json:"author_id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int64      `json:"id"`
+	AuthorID    int64      `json:"author_id"`
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug"`
+	Content     string     `json:"content"`
+	Tags        []string   `json:"tags"`
+	Status      Status     `json:"status"`
+	PublishedAt *time.Time `json:"published_at"`
+	PublishAt   *time.Time `json:"publish_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
-// CreatePostRequest is the expected JSON payload for creating a post.
+// newPostResponse converts a post into its JSON view, rendering unset timestamps as null.
+func newPostResponse(post Post) CreatePostResponse {
+	resp := CreatePostResponse{
+		ID:        post.ID,
+		AuthorID:  post.AuthorID,
+		Title:     post.Title,
+		Slug:      post.Slug,
+		Content:   post.Content,
+		Tags:      post.Tags,
+		Status:    post.Status,
+		CreatedAt: post.CreatedAt,
+		UpdatedAt: post.UpdatedAt,
+	}
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+	if !post.PublishedAt.IsZero() {
+		resp.PublishedAt = &post.PublishedAt
+	}
+	if !post.PublishAt.IsZero() {
+		resp.PublishAt = &post.PublishAt
+	}
+	return resp
+}
+
+// CreatePostRequest is the expected JSON payload for creating a post. Tags are optional.
 type CreatePostRequest struct {
-	Title   string `json:"title" validate:"required,min=1"`
-	Content string `json:"content" validate:"required,min=1"`
+	Title   string   `json:"title" validate:"required,min=1"`
+	Content string   `json:"content" validate:"required,min=1"`
+	Tags    []string `json:"tags" validate:"omitempty,dive,required"`
 }
 
 // CreatePost handles authenticated post creation requests.
@@ -74,14 +109,227 @@ func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		AuthorID: authUser.ID,
 		Title:    req.Title,
 		Content:  req.Content,
+		Tags:     req.Tags,
 	})
 
 	if err != nil {
-		httpx.WriteError(w, http.StatusInternalServerError, err)
+		switch {
+		case errors.Is(err, ErrInvalidTag), errors.Is(err, ErrTooManyTags):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, newPostResponse(post))
+}
+
+// ReplacePostRequest is the expected JSON payload for replacing a post with PUT. Omitted tags
+// remove every tag from the post.
+type ReplacePostRequest struct {
+	Title   string   `json:"title" validate:"required,min=1"`
+	Content string   `json:"content" validate:"required,min=1"`
+	Tags    []string `json:"tags" validate:"omitempty,dive,required"`
+}
+
+// PatchPostRequest is the expected JSON payload for partially updating a post with PATCH.
+// Omitted fields are left untouched; tags, when present, replace the post's tags.
+type PatchPostRequest struct {
+	Title   *string   `json:"title" validate:"omitnil,min=1"`
+	Content *string   `json:"content" validate:"omitnil,min=1"`
+	Tags    *[]string `json:"tags" validate:"omitnil,dive,required"`
+}
+
+// ReplacePost handles PUT requests that overwrite the title, the content and the tags of a post.
+func (h *Handler) ReplacePost(w http.ResponseWriter, r *http.Request) {
+	var req ReplacePostRequest
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.updatePost(w, r, UpdatePostInput{Title: &req.Title, Content: &req.Content, Tags: &req.Tags})
+}
+
+// PatchPost handles PATCH requests that change only the fields present in the body.
+func (h *Handler) PatchPost(w http.ResponseWriter, r *http.Request) {
+	var req PatchPostRequest
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.updatePost(w, r, UpdatePostInput(req))
+}
+
+// updatePost applies input to the post in the URL on behalf of the authenticated user.
+func (h *Handler) updatePost(w http.ResponseWriter, r *http.Request, input UpdatePostInput) {
+	authUser, ok := user.AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, errors.New("auth user can not found"))
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := h.svc.UpdatePost(r.Context(), authUser.ID, authUser.Can(user.PermPostsEditAny), id, input)
+	if err != nil {
+		writeChangeError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, newPostResponse(post))
+}
+
+// PublishPost makes a draft visible to everyone and stamps its published_at.
+func (h *Handler) PublishPost(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, StatusPublished)
+}
+
+// ArchivePost takes a published post out of listings while keeping it reachable by its link.
+func (h *Handler) ArchivePost(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, StatusArchived)
+}
+
+// RevertPostToDraft hides a published or archived post from everyone but its author again.
+func (h *Handler) RevertPostToDraft(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, StatusDraft)
+}
+
+// changeStatus moves the post in the URL to status on behalf of the authenticated user.
+func (h *Handler) changeStatus(w http.ResponseWriter, r *http.Request, status Status) {
+	authUser, ok := user.AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, errors.New("auth user can not found"))
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := h.svc.ChangeStatus(r.Context(), authUser.ID, authUser.Can(user.PermPostsEditAny), id, status)
+	if err != nil {
+		writeChangeError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, newPostResponse(post))
+}
+
+// SchedulePostRequest is the expected JSON payload for scheduling a draft.
+type SchedulePostRequest struct {
+	PublishAt time.Time `json:"publishAt" validate:"required"`
+}
+
+// SchedulePost sets or moves the time a draft is published automatically.
+func (h *Handler) SchedulePost(w http.ResponseWriter, r *http.Request) {
+	var req SchedulePostRequest
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.schedulePost(w, r, req.PublishAt)
+}
+
+// UnschedulePost cancels the automatic publication of a draft.
+func (h *Handler) UnschedulePost(w http.ResponseWriter, r *http.Request) {
+	h.schedulePost(w, r, time.Time{})
+}
+
+// schedulePost sets the publish time of the post in the URL on behalf of the authenticated user.
+func (h *Handler) schedulePost(w http.ResponseWriter, r *http.Request, publishAt time.Time) {
+	authUser, ok := user.AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, errors.New("auth user can not found"))
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := h.svc.SchedulePost(r.Context(), authUser.ID, authUser.Can(user.PermPostsEditAny), id, publishAt)
+	if err != nil {
+		writeChangeError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, newPostResponse(post))
+}
+
+// DeletePost handles requests to delete a post by its author.
+func (h *Handler) DeletePost(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := user.AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, errors.New("auth user can not found"))
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	httpx.WriteJSON(w, http.StatusCreated, CreatePostResponse(post))
+	if err := h.svc.DeletePost(r.Context(), authUser.ID, authUser.Can(user.PermPostsEditAny), id); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeChangeError maps errors from updating or deleting a post to HTTP responses.
+func writeChangeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrPostNotFound):
+		httpx.WriteError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrNotPostAuthor):
+		httpx.WriteError(w, http.StatusForbidden, err)
+	case errors.Is(err, ErrInvalidStatus):
+		httpx.WriteError(w, http.StatusConflict, err)
+	case errors.Is(err, ErrInvalidSchedule), errors.Is(err, ErrInvalidTag), errors.Is(err, ErrTooManyTags):
+		httpx.WriteError(w, http.StatusBadRequest, err)
+	case errors.Is(err, ErrSlugTaken):
+		httpx.WriteError(w, http.StatusConflict, err)
+	default:
+		httpx.WriteError(w, http.StatusInternalServerError, err)
+	}
 }
 
 // GetAllPostsResponse is the JSON response body for listing all posts.
@@ -92,8 +340,95 @@ type GetAllPostsResponse struct {
 	Posts  []Row `json:"posts"`
 }
 
-// GetAllPosts handles paginated requests to list all posts.
+// GetAllPosts handles paginated requests to list all posts. Repeated tag query parameters narrow
+// the list to posts with any of the tags, or with all of them when match=all is given.
 func (h *Handler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
+	input, err := parseListInput(r)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	filter, err := parseTagFilter(r)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	posts, err := h.svc.GetAllPosts(r.Context(), viewerID(r), filter, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidTag), errors.Is(err, ErrTooManyTags):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	normalized := NormalizeListInput(input)
+	httpx.WriteJSON(w, http.StatusOK, GetAllPostsResponse{
+		Posts:  posts,
+		Count:  len(posts),
+		Limit:  normalized.Limit,
+		Offset: normalized.Offset,
+	})
+}
+
+// GetPostsByAuthor handles paginated requests to list one author's posts.
+func (h *Handler) GetPostsByAuthor(w http.ResponseWriter, r *http.Request) {
+	input, err := parseListInput(r)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	posts, err := h.svc.GetPostsByAuthor(r.Context(), viewerID(r), chi.URLParam(r, "username"), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAuthorNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	normalized := NormalizeListInput(input)
+	httpx.WriteJSON(w, http.StatusOK, GetAllPostsResponse{
+		Posts:  posts,
+		Count:  len(posts),
+		Limit:  normalized.Limit,
+		Offset: normalized.Offset,
+	})
+}
+
+// viewerID returns the ID of the signed-in reader on public routes, or zero for anonymous requests.
+func viewerID(r *http.Request) int64 {
+	authUser, ok := user.AuthUserFromContext(r.Context())
+	if !ok {
+		return 0
+	}
+	return authUser.ID
+}
+
+// parseTagFilter reads the repeated tag query parameter and the optional match mode, any or all.
+func parseTagFilter(r *http.Request) (TagFilter, error) {
+	query := r.URL.Query()
+	filter := TagFilter{Tags: query["tag"]}
+
+	switch query.Get("match") {
+	case "", "any":
+	case "all":
+		filter.MatchAll = true
+	default:
+		return TagFilter{}, ErrInvalidTagMatch
+	}
+	return filter, nil
+}
+
+// parseListInput reads the optional limit and offset query parameters.
+func parseListInput(r *http.Request) (ListPostsInput, error) {
 	limitStr := r.URL.Query().Get("limit")
 	offsetStr := r.URL.Query().Get("offset")
 
@@ -103,36 +438,70 @@ func (h *Handler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 	if limitStr != "" {
 		limit, err = strconv.ParseInt(limitStr, 10, 32)
 		if err != nil {
-			httpx.WriteError(w, http.StatusBadRequest, errors.New("invalid limit parameter"))
-			return
+			return ListPostsInput{}, errors.New("invalid limit parameter")
 		}
 	}
 
 	if offsetStr != "" {
 		offset, err = strconv.ParseInt(offsetStr, 10, 32)
 		if err != nil {
-			httpx.WriteError(w, http.StatusBadRequest, errors.New("invalid offset parameter"))
-			return
+			return ListPostsInput{}, errors.New("invalid offset parameter")
 		}
 	}
 
-	input := ListPostsInput{
+	return ListPostsInput{
 		Limit:  int32(limit),
 		Offset: int32(offset),
+	}, nil
+}
+
+// FeedResponse is the JSON response body for the home feed.
+type FeedResponse struct {
+	Count      int    `json:"count"`
+	Limit      int32  `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Posts      []Row  `json:"posts"`
+}
+
+// GetFeed handles keyset-paginated requests for posts by the authors the caller follows.
+func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := user.AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, errors.New("auth user can not found"))
+		return
 	}
 
-	posts, err := h.svc.GetAllPosts(r.Context(), input)
+	var limit int64
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.ParseInt(limitStr, 10, 32)
+		if err != nil {
+			httpx.WriteError(w, http.StatusBadRequest, errors.New("invalid limit parameter"))
+			return
+		}
+	}
+
+	input := FeedInput{
+		Cursor: r.URL.Query().Get("cursor"),
+		Limit:  int32(limit),
+	}
+
+	feed, err := h.svc.GetFeed(r.Context(), authUser.ID, input)
 	if err != nil {
-		httpx.WriteError(w, http.StatusInternalServerError, err)
+		switch {
+		case errors.Is(err, ErrInvalidCursor):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
 		return
 	}
 
-	normalized := NormalizeListInput(input)
-	httpx.WriteJSON(w, http.StatusOK, GetAllPostsResponse{
-		Posts:  posts,
-		Count:  len(posts),
-		Limit:  normalized.Limit,
-		Offset: normalized.Offset,
+	httpx.WriteJSON(w, http.StatusOK, FeedResponse{
+		Posts:      feed.Posts,
+		Count:      len(feed.Posts),
+		Limit:      NormalizeListInput(ListPostsInput{Limit: input.Limit}).Limit,
+		NextCursor: feed.NextCursor,
 	})
 }
 
@@ -152,7 +521,7 @@ func (h *Handler) GetPostByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := h.svc.GetPostByID(r.Context(), id)
+	post, err := h.svc.GetPostByID(r.Context(), viewerID(r), id)
 
 	if err != nil {
 		httpx.WriteError(w, http.StatusNotFound, err)
@@ -162,14 +531,108 @@ func (h *Handler) GetPostByID(w http.ResponseWriter, r *http.Request) {
 	httpx.WriteJSON(w, http.StatusOK, post)
 }
 
+// GetPostBySlug handles permalink requests for a post by its slug. Slugs a post had before it was
+// renamed answer with a permanent redirect to its current permalink.
+func (h *Handler) GetPostBySlug(w http.ResponseWriter, r *http.Request) {
+	post, err := h.svc.GetPostBySlug(r.Context(), viewerID(r), chi.URLParam(r, "slug"))
+	if err != nil {
+		var moved *MovedError
+		switch {
+		case errors.As(err, &moved):
+			target := path.Join(path.Dir(r.URL.Path), moved.Slug)
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+		case errors.Is(err, ErrPostNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, post)
+}
+
+// TagResponse is one entry of the tag listing.
+type TagResponse struct {
+	Name      string `json:"name"`
+	PostCount int64  `json:"post_count"`
+}
+
+// ListTagsResponse is the JSON response body for listing tags.
+type ListTagsResponse struct {
+	Count  int           `json:"count"`
+	Limit  int32         `json:"limit"`
+	Offset int32         `json:"offset"`
+	Tags   []TagResponse `json:"tags"`
+}
+
+// ListTags handles paginated requests for the tags in use, with their published post counts.
+func (h *Handler) ListTags(w http.ResponseWriter, r *http.Request) {
+	input, err := parseListInput(r)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tags, err := h.svc.ListTags(r.Context(), input)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := make([]TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		resp = append(resp, TagResponse(tag))
+	}
+
+	normalized := NormalizeListInput(input)
+	httpx.WriteJSON(w, http.StatusOK, ListTagsResponse{
+		Tags:   resp,
+		Count:  len(resp),
+		Limit:  normalized.Limit,
+		Offset: normalized.Offset,
+	})
+}
+
 // Routes registers post HTTP routes under the provided chi router.
 func (h *Handler) Routes(r chi.Router) chi.Router {
-	r.Get("/", h.GetAllPosts)
-	r.Get("/{id}", h.GetPostByID)
+	r.With(h.optionalAuthMW).Get("/", h.GetAllPosts)
+	r.With(h.optionalAuthMW).Get("/{id}", h.GetPostByID)
+	r.With(h.optionalAuthMW).Get("/by-slug/{slug}", h.GetPostBySlug)
+	r.Group(func(r chi.Router) {
+		r.Use(h.authMW)
+		r.Use(user.RequireScope(user.ScopePostsWrite))
+		r.With(user.RequirePermission(user.PermPostsCreate)).Post("/", h.CreatePost)
+		r.Put("/{id}", h.ReplacePost)
+		r.Patch("/{id}", h.PatchPost)
+		r.Delete("/{id}", h.DeletePost)
+		r.Post("/{id}/publish", h.PublishPost)
+		r.Post("/{id}/archive", h.ArchivePost)
+		r.Post("/{id}/draft", h.RevertPostToDraft)
+		r.Put("/{id}/schedule", h.SchedulePost)
+		r.Delete("/{id}/schedule", h.UnschedulePost)
+	})
+
+	return r
+}
+
+// FeedRoutes registers the authenticated home feed under the provided chi router.
+func (h *Handler) FeedRoutes(r chi.Router) chi.Router {
 	r.Group(func(r chi.Router) {
 		r.Use(h.authMW)
-		r.Post("/", h.CreatePost)
+		r.Use(user.RequireScope(user.ScopePostsRead))
+		r.Get("/", h.GetFeed)
 	})
 
 	return r
 }
+
+// TagRoutes registers the public tag listing under the provided chi router.
+func (h *Handler) TagRoutes(r chi.Router) chi.Router {
+	r.Get("/", h.ListTags)
+
+	return r
+}
@@ -2,69 +2,138 @@ package post
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/OnatArslan/devlog/internal/sqlc"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Repository provides post persistence operations backed by sqlc queries.
 type Repository struct {
-	q *sqlc.Queries
+	db *pgxpool.Pool
+	q  *sqlc.Queries
 }
 
-// NewPostRepository creates a Repository wired to the given sqlc query set.
-func NewPostRepository(q *sqlc.Queries) *Repository {
+// NewPostRepository creates a Repository backed by sqlc queries on the given pool. The pool is
+// kept as well for slug changes, which must run in a transaction.
+func NewPostRepository(db *pgxpool.Pool) *Repository {
 	return &Repository{
-		q: q,
+		db: db,
+		q:  sqlc.New(db),
 	}
 }
 
-// CreatePostParams defines the input fields required to insert a new post row.
+// CreatePostParams defines the input fields required to insert a new post row. Tags must already
+// be normalized.
 type CreatePostParams struct {
 	AuthorID int64
 	Title    string
 	Content  string
+	Slug     string
+	Tags     []string
 }
 
-// CreatePost inserts a new post and returns the created domain model.
+// CreatePost inserts a new post together with its tags, creating tags that do not exist yet, and
+// returns the created domain model.
 func (r *Repository) CreatePost(ctx context.Context, params CreatePostParams) (Post, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return Post{}, fmt.Errorf("repository create post begin: %w", err)
+	}
+	// Rollback is a no-op once the transaction has been committed.
+	defer tx.Rollback(ctx)
 
-	row, err := r.q.CreatePost(ctx, sqlc.CreatePostParams{
+	qtx := r.q.WithTx(tx)
+	row, err := qtx.CreatePost(ctx, sqlc.CreatePostParams{
 		AuthorID: params.AuthorID,
 		Title:    params.Title,
 		Content:  params.Content,
+		Slug:     params.Slug,
 	})
 	if err != nil {
+		if isSlugConflict(err) {
+			return Post{}, ErrSlugTaken
+		}
 		return Post{}, fmt.Errorf("error on repo: %w", err)
 	}
 
+	if len(params.Tags) > 0 {
+		// Tags are linked in a second statement so it sees tags another post created concurrently.
+		if err := qtx.UpsertTags(ctx, params.Tags); err != nil {
+			return Post{}, fmt.Errorf("repository upsert tags: %w", err)
+		}
+		if err := qtx.AddPostTags(ctx, sqlc.AddPostTagsParams{
+			PostID: row.ID,
+			Names:  params.Tags,
+		}); err != nil {
+			return Post{}, fmt.Errorf("repository add post tags: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Post{}, fmt.Errorf("repository create post commit: %w", err)
+	}
+
 	return Post{
-		ID:        row.ID,
-		AuthorID:  row.AuthorID,
-		Title:     row.Title,
-		Content:   row.Content,
-		CreatedAt: row.CreatedAt.Time,
-		UpdatedAt: row.UpdatedAt.Time,
+		ID:          row.ID,
+		AuthorID:    row.AuthorID,
+		Title:       row.Title,
+		Slug:        row.Slug,
+		Content:     row.Content,
+		Tags:        params.Tags,
+		Status:      Status(row.Status),
+		PublishedAt: row.PublishedAt.Time,
+		PublishAt:   row.PublishAt.Time,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
 	}, nil
 }
 
 // Row is a post enriched with the author's username, used in list/detail responses.
 type Row struct {
-	ID        int64     `json:"id"`
-	AuthorID  int64     `json:"author_id"`
-	Username  string    `json:"author_username"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	UpdatedAt time.Time `json:"updated_at"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          int64      `json:"id"`
+	AuthorID    int64      `json:"author_id"`
+	Username    string     `json:"author_username"`
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug"`
+	Content     string     `json:"content"`
+	Tags        []string   `json:"tags"`
+	Status      Status     `json:"status"`
+	PublishedAt *time.Time `json:"published_at"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// isSlugConflict reports whether err is a unique violation on a post slug.
+func isSlugConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation &&
+		pgErr.ConstraintName == "posts_slug_key"
 }
 
-// GetAllPosts returns paginated posts joined with their author username.
-func (r *Repository) GetAllPosts(ctx context.Context, limit, offset int32) ([]Row, error) {
+// timePtr renders an unset timestamp as nil so it is encoded as JSON null.
+func timePtr(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// GetAllPosts returns paginated posts joined with their author username, leaving out authors
+// viewerID has blocked or muted and authors who blocked viewerID. viewerID is zero for anonymous readers.
+func (r *Repository) GetAllPosts(ctx context.Context, viewerID int64, limit, offset int32) ([]Row, error) {
 	rows, err := r.q.GetAllPosts(ctx, sqlc.GetAllPostsParams{
-		Limit:  limit,
-		Offset: offset,
+		ViewerID:   viewerID,
+		PageLimit:  limit,
+		PageOffset: offset,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("repository get all posts: %w", err)
@@ -72,33 +141,479 @@ func (r *Repository) GetAllPosts(ctx context.Context, limit, offset int32) ([]Ro
 	posts := make([]Row, 0, len(rows))
 	for _, row := range rows {
 		posts = append(posts, Row{
-			ID:        row.ID,
-			AuthorID:  row.AuthorID,
-			Username:  row.Username,
-			Title:     row.Title,
-			Content:   row.Content,
-			UpdatedAt: row.UpdatedAt.Time,
-			CreatedAt: row.CreatedAt.Time,
+			ID:          row.ID,
+			AuthorID:    row.AuthorID,
+			Username:    row.Username,
+			Title:       row.Title,
+			Slug:        row.Slug,
+			Content:     row.Content,
+			Tags:        row.Tags,
+			Status:      Status(row.Status),
+			PublishedAt: timePtr(row.PublishedAt),
+			PublishAt:   timePtr(row.PublishAt),
+			UpdatedAt:   row.UpdatedAt.Time,
+			CreatedAt:   row.CreatedAt.Time,
+		})
+	}
+	return posts, nil
+}
+
+// GetPostsByTags is GetAllPosts restricted to posts tagged with any of tags, or with all of them
+// when matchAll is set. Tags must already be normalized, which also removes duplicates.
+func (r *Repository) GetPostsByTags(ctx context.Context, viewerID int64, tags []string, matchAll bool, limit, offset int32) ([]Row, error) {
+	rows, err := r.q.GetPostsByTags(ctx, sqlc.GetPostsByTagsParams{
+		Tags:       tags,
+		MatchAll:   matchAll,
+		ViewerID:   viewerID,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository get posts by tags: %w", err)
+	}
+	posts := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		posts = append(posts, Row{
+			ID:          row.ID,
+			AuthorID:    row.AuthorID,
+			Username:    row.Username,
+			Title:       row.Title,
+			Slug:        row.Slug,
+			Content:     row.Content,
+			Tags:        row.Tags,
+			Status:      Status(row.Status),
+			PublishedAt: timePtr(row.PublishedAt),
+			PublishAt:   timePtr(row.PublishAt),
+			UpdatedAt:   row.UpdatedAt.Time,
+			CreatedAt:   row.CreatedAt.Time,
 		})
 	}
 	return posts, nil
 }
 
-// GetPostByID returns a single post with author username by post ID.
-func (r *Repository) GetPostByID(ctx context.Context, id int64) (Row, error) {
+// ListTags returns a page of tags with how many published posts carry each, most used first.
+func (r *Repository) ListTags(ctx context.Context, limit, offset int32) ([]Tag, error) {
+	rows, err := r.q.ListTags(ctx, sqlc.ListTagsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository list tags: %w", err)
+	}
+	tags := make([]Tag, 0, len(rows))
+	for _, row := range rows {
+		tags = append(tags, Tag{
+			Name:      row.Name,
+			PostCount: row.PostCount,
+		})
+	}
+	return tags, nil
+}
+
+// GetPostByID returns a single post with author username by post ID. Posts whose author blocked
+// viewerID are not found.
+func (r *Repository) GetPostByID(ctx context.Context, viewerID, id int64) (Row, error) {
 
-	row, err := r.q.GetPostById(ctx, id)
+	row, err := r.q.GetPostById(ctx, sqlc.GetPostByIdParams{
+		ID:       id,
+		ViewerID: viewerID,
+	})
 	if err != nil {
 		return Row{}, err
 	}
 
 	return Row{
-		ID:        row.ID,
-		AuthorID:  row.AuthorID,
-		Username:  row.Username,
-		Title:     row.Title,
-		Content:   row.Content,
-		UpdatedAt: row.UpdatedAt.Time,
-		CreatedAt: row.CreatedAt.Time,
+		ID:          row.ID,
+		AuthorID:    row.AuthorID,
+		Username:    row.Username,
+		Title:       row.Title,
+		Slug:        row.Slug,
+		Content:     row.Content,
+		Tags:        row.Tags,
+		Status:      Status(row.Status),
+		PublishedAt: timePtr(row.PublishedAt),
+		PublishAt:   timePtr(row.PublishAt),
+		UpdatedAt:   row.UpdatedAt.Time,
+		CreatedAt:   row.CreatedAt.Time,
+	}, nil
+}
+
+// GetPostBySlug returns a single post with author username by its current slug, under the same
+// visibility rules as GetPostByID.
+func (r *Repository) GetPostBySlug(ctx context.Context, viewerID int64, slug string) (Row, error) {
+	row, err := r.q.GetPostBySlug(ctx, sqlc.GetPostBySlugParams{
+		Slug:     slug,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Row{}, ErrPostNotFound
+		}
+		return Row{}, fmt.Errorf("repository get post by slug: %w", err)
+	}
+
+	return Row{
+		ID:          row.ID,
+		AuthorID:    row.AuthorID,
+		Username:    row.Username,
+		Title:       row.Title,
+		Slug:        row.Slug,
+		Content:     row.Content,
+		Tags:        row.Tags,
+		Status:      Status(row.Status),
+		PublishedAt: timePtr(row.PublishedAt),
+		PublishAt:   timePtr(row.PublishAt),
+		UpdatedAt:   row.UpdatedAt.Time,
+		CreatedAt:   row.CreatedAt.Time,
+	}, nil
+}
+
+// GetSlugRedirect returns the current slug of the post that used to be addressed by slug. It
+// returns ErrPostNotFound when no post viewerID may see ever had that slug.
+func (r *Repository) GetSlugRedirect(ctx context.Context, viewerID int64, slug string) (string, error) {
+	current, err := r.q.GetSlugRedirect(ctx, sqlc.GetSlugRedirectParams{
+		Slug:     slug,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrPostNotFound
+		}
+		return "", fmt.Errorf("repository get slug redirect: %w", err)
+	}
+	return current, nil
+}
+
+// GetPostSlug returns the current slug of the post.
+func (r *Repository) GetPostSlug(ctx context.Context, id int64) (string, error) {
+	slug, err := r.q.GetPostSlug(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrPostNotFound
+		}
+		return "", fmt.Errorf("repository get post slug: %w", err)
+	}
+	return slug, nil
+}
+
+// ListTakenSlugs returns the slugs made of base and an optional "-N" suffix that posts other than
+// postID use now or used before. postID is zero for a post that does not exist yet.
+func (r *Repository) ListTakenSlugs(ctx context.Context, postID int64, base string) (map[string]bool, error) {
+	slugs, err := r.q.ListTakenSlugs(ctx, sqlc.ListTakenSlugsParams{
+		Base:   base,
+		PostID: postID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository list taken slugs: %w", err)
+	}
+	taken := make(map[string]bool, len(slugs))
+	for _, slug := range slugs {
+		taken[slug] = true
+	}
+	return taken, nil
+}
+
+// GetPostAuthorID returns the ID of the user who wrote the post.
+func (r *Repository) GetPostAuthorID(ctx context.Context, id int64) (int64, error) {
+	authorID, err := r.q.GetPostAuthorID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrPostNotFound
+		}
+		return 0, fmt.Errorf("repository get post author id: %w", err)
+	}
+	return authorID, nil
+}
+
+// UpdatePostParams lists the post fields to change. Nil fields are left untouched; Tags replaces
+// the post's tags, so an empty list removes them all.
+type UpdatePostParams struct {
+	Title   *string
+	Content *string
+	Slug    *string
+	Tags    *[]string
+}
+
+// UpdatePost applies params to the post and bumps its updated_at. A new slug moves the current one
+// into the slug history in the same transaction, so old links redirect from the moment it changes.
+func (r *Repository) UpdatePost(ctx context.Context, id int64, params UpdatePostParams) (Post, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return Post{}, fmt.Errorf("repository update post begin: %w", err)
+	}
+	// Rollback is a no-op once the transaction has been committed.
+	defer tx.Rollback(ctx)
+
+	qtx := r.q.WithTx(tx)
+
+	if params.Slug != nil {
+		if err := qtx.SavePostSlugHistory(ctx, id); err != nil {
+			return Post{}, fmt.Errorf("repository save post slug history: %w", err)
+		}
+		// A post taking back one of its earlier slugs no longer redirects from it.
+		if err := qtx.DeletePostSlugHistory(ctx, sqlc.DeletePostSlugHistoryParams{
+			Slug:   *params.Slug,
+			PostID: id,
+		}); err != nil {
+			return Post{}, fmt.Errorf("repository delete post slug history: %w", err)
+		}
+	}
+
+	row, err := qtx.UpdatePost(ctx, sqlc.UpdatePostParams{
+		Title:   optionalText(params.Title),
+		Content: optionalText(params.Content),
+		Slug:    optionalText(params.Slug),
+		ID:      id,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Post{}, ErrPostNotFound
+		}
+		if isSlugConflict(err) {
+			return Post{}, ErrSlugTaken
+		}
+		return Post{}, fmt.Errorf("repository update post: %w", err)
+	}
+
+	if params.Tags != nil {
+		// A nil slice would be sent as NULL, which matches no tag and would keep them all.
+		names := *params.Tags
+		if names == nil {
+			names = []string{}
+		}
+		if err := qtx.UpsertTags(ctx, names); err != nil {
+			return Post{}, fmt.Errorf("repository upsert tags: %w", err)
+		}
+		if err := qtx.RemovePostTagsNotIn(ctx, sqlc.RemovePostTagsNotInParams{
+			PostID: id,
+			Names:  names,
+		}); err != nil {
+			return Post{}, fmt.Errorf("repository remove post tags: %w", err)
+		}
+		if err := qtx.AddPostTags(ctx, sqlc.AddPostTagsParams{
+			PostID: id,
+			Names:  names,
+		}); err != nil {
+			return Post{}, fmt.Errorf("repository add post tags: %w", err)
+		}
+	}
+
+	tags, err := qtx.ListPostTags(ctx, id)
+	if err != nil {
+		return Post{}, fmt.Errorf("repository list post tags: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Post{}, fmt.Errorf("repository update post commit: %w", err)
+	}
+
+	return Post{
+		ID:          row.ID,
+		AuthorID:    row.AuthorID,
+		Title:       row.Title,
+		Slug:        row.Slug,
+		Content:     row.Content,
+		Tags:        tags,
+		Status:      Status(row.Status),
+		PublishedAt: row.PublishedAt.Time,
+		PublishAt:   row.PublishAt.Time,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}, nil
+}
+
+// TransitionPostStatus moves the post to status if it currently is in one of from. It returns
+// ErrInvalidStatus when the post is in any other state.
+func (r *Repository) TransitionPostStatus(ctx context.Context, id int64, status Status, from []Status) (Post, error) {
+	fromStatuses := make([]string, 0, len(from))
+	for _, f := range from {
+		fromStatuses = append(fromStatuses, string(f))
+	}
+
+	row, err := r.q.TransitionPostStatus(ctx, sqlc.TransitionPostStatusParams{
+		ToStatus:     string(status),
+		ID:           id,
+		FromStatuses: fromStatuses,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Post{}, ErrInvalidStatus
+		}
+		return Post{}, fmt.Errorf("repository transition post status: %w", err)
+	}
+
+	tags, err := r.q.ListPostTags(ctx, id)
+	if err != nil {
+		return Post{}, fmt.Errorf("repository list post tags: %w", err)
+	}
+
+	return Post{
+		ID:          row.ID,
+		AuthorID:    row.AuthorID,
+		Title:       row.Title,
+		Slug:        row.Slug,
+		Content:     row.Content,
+		Tags:        tags,
+		Status:      Status(row.Status),
+		PublishedAt: row.PublishedAt.Time,
+		PublishAt:   row.PublishAt.Time,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
 	}, nil
 }
+
+// SchedulePost sets the time a draft goes live, or clears it when publishAt is zero. It returns
+// ErrInvalidStatus when the post is not a draft.
+func (r *Repository) SchedulePost(ctx context.Context, id int64, publishAt time.Time) (Post, error) {
+	row, err := r.q.SchedulePost(ctx, sqlc.SchedulePostParams{
+		PublishAt: pgtype.Timestamptz{Time: publishAt, Valid: !publishAt.IsZero()},
+		ID:        id,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Post{}, ErrInvalidStatus
+		}
+		return Post{}, fmt.Errorf("repository schedule post: %w", err)
+	}
+
+	tags, err := r.q.ListPostTags(ctx, id)
+	if err != nil {
+		return Post{}, fmt.Errorf("repository list post tags: %w", err)
+	}
+
+	return Post{
+		ID:          row.ID,
+		AuthorID:    row.AuthorID,
+		Title:       row.Title,
+		Slug:        row.Slug,
+		Content:     row.Content,
+		Tags:        tags,
+		Status:      Status(row.Status),
+		PublishedAt: row.PublishedAt.Time,
+		PublishAt:   row.PublishAt.Time,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}, nil
+}
+
+// PublishDuePosts publishes up to limit scheduled drafts whose time has come and returns their IDs.
+func (r *Repository) PublishDuePosts(ctx context.Context, limit int32) ([]int64, error) {
+	ids, err := r.q.PublishDuePosts(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository publish due posts: %w", err)
+	}
+	return ids, nil
+}
+
+// DeletePost removes the post.
+func (r *Repository) DeletePost(ctx context.Context, id int64) error {
+	n, err := r.q.DeletePost(ctx, id)
+	if err != nil {
+		return fmt.Errorf("repository delete post: %w", err)
+	}
+	if n == 0 {
+		return ErrPostNotFound
+	}
+	return nil
+}
+
+// optionalText converts an optional string into a nullable text parameter.
+func optionalText(s *string) pgtype.Text {
+	if s == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *s, Valid: true}
+}
+
+// GetActiveAuthorID resolves an active user's username to their ID. Authors who blocked viewerID
+// are reported as not found.
+func (r *Repository) GetActiveAuthorID(ctx context.Context, viewerID int64, username string) (int64, error) {
+	id, err := r.q.GetActiveAuthorID(ctx, sqlc.GetActiveAuthorIDParams{
+		Username: username,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrAuthorNotFound
+		}
+		return 0, fmt.Errorf("repository get author id: %w", err)
+	}
+	return id, nil
+}
+
+// GetPostsByAuthor returns one author's posts, newest first. Unpublished posts are only included
+// when viewerID is the author.
+func (r *Repository) GetPostsByAuthor(ctx context.Context, viewerID, authorID int64, limit, offset int32) ([]Row, error) {
+	rows, err := r.q.GetPostsByAuthor(ctx, sqlc.GetPostsByAuthorParams{
+		AuthorID:   authorID,
+		ViewerID:   viewerID,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository get posts by author: %w", err)
+	}
+	posts := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		posts = append(posts, Row{
+			ID:          row.ID,
+			AuthorID:    row.AuthorID,
+			Username:    row.Username,
+			Title:       row.Title,
+			Slug:        row.Slug,
+			Content:     row.Content,
+			Tags:        row.Tags,
+			Status:      Status(row.Status),
+			PublishedAt: timePtr(row.PublishedAt),
+			PublishAt:   timePtr(row.PublishAt),
+			UpdatedAt:   row.UpdatedAt.Time,
+			CreatedAt:   row.CreatedAt.Time,
+		})
+	}
+	return posts, nil
+}
+
+// FeedCursor marks the last post of a feed page. The next page starts strictly after it in
+// (published_at, id) order, which stays stable while new posts are being published.
+type FeedCursor struct {
+	PublishedAt time.Time
+	ID          int64
+}
+
+// GetFeed returns up to limit posts by authors followerID follows and has not muted, newest first.
+// A zero cursor starts from the newest post.
+func (r *Repository) GetFeed(ctx context.Context, followerID int64, cursor FeedCursor, limit int32) ([]Row, error) {
+	before := pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true}
+	beforeID := int64(math.MaxInt64)
+	if !cursor.PublishedAt.IsZero() {
+		before = pgtype.Timestamptz{Time: cursor.PublishedAt, Valid: true}
+		beforeID = cursor.ID
+	}
+
+	rows, err := r.q.GetFeed(ctx, sqlc.GetFeedParams{
+		BeforePublishedAt: before,
+		BeforeID:          beforeID,
+		PageSize:          limit,
+		FollowerID:        followerID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository get feed: %w", err)
+	}
+	posts := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		posts = append(posts, Row{
+			ID:          row.ID,
+			AuthorID:    row.AuthorID,
+			Username:    row.Username,
+			Title:       row.Title,
+			Slug:        row.Slug,
+			Content:     row.Content,
+			Tags:        row.Tags,
+			Status:      Status(row.Status),
+			PublishedAt: timePtr(row.PublishedAt),
+			UpdatedAt:   row.UpdatedAt.Time,
+			CreatedAt:   row.CreatedAt.Time,
+		})
+	}
+	return posts, nil
+}
@@ -2,7 +2,15 @@ package post
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OnatArslan/devlog/internal/periodic"
 )
 
 // Service contains business logic for post operations.
@@ -17,23 +25,227 @@ func NewPostService(repo *Repository) *Service {
 	}
 }
 
-// CreatePostInput defines the fields required to create a new post.
+// CreatePostInput defines the fields required to create a new post. Tags are optional.
 type CreatePostInput struct {
 	AuthorID int64
 	Title    string
 	Content  string
+	Tags     []string
 }
 
-// CreatePost creates a new post and returns the persisted domain model.
+// CreatePost creates a new draft post with a unique slug derived from its title and returns the
+// persisted domain model. Tags are lowercased and deduplicated; unknown ones are created.
 func (s *Service) CreatePost(ctx context.Context, input CreatePostInput) (Post, error) {
+	tags, err := normalizeTags(input.Tags)
+	if err != nil {
+		return Post{}, err
+	}
+
+	base := Slugify(input.Title)
+	for attempt := 1; ; attempt++ {
+		slug, err := s.uniqueSlug(ctx, 0, base)
+		if err != nil {
+			return Post{}, fmt.Errorf("create post service : %w", err)
+		}
+
+		post, err := s.repo.CreatePost(ctx, CreatePostParams{
+			AuthorID: input.AuthorID,
+			Title:    input.Title,
+			Content:  input.Content,
+			Slug:     slug,
+			Tags:     tags,
+		})
+		// Another post may have claimed the same slug since it was picked; pick again.
+		if errors.Is(err, ErrSlugTaken) && attempt < slugAttempts {
+			continue
+		}
+		if err != nil {
+			return Post{}, fmt.Errorf("create post service : %w", err)
+		}
+		return post, nil
+	}
+}
+
+// slugAttempts bounds how often a slug is picked again after losing a race for it.
+const slugAttempts = 3
+
+// uniqueSlug returns base, or base with the lowest "-N" suffix from 2 up, that no post other than
+// postID uses or used before.
+func (s *Service) uniqueSlug(ctx context.Context, postID int64, base string) (string, error) {
+	taken, err := s.repo.ListTakenSlugs(ctx, postID, base)
+	if err != nil {
+		return "", err
+	}
+	if !taken[base] {
+		return base, nil
+	}
+	for n := 2; ; n++ {
+		slug := base + "-" + strconv.Itoa(n)
+		if !taken[slug] {
+			return slug, nil
+		}
+	}
+}
+
+// slugForTitle returns the slug the post should move to for its new title, or nil while the
+// current slug still matches the title. Keeping it avoids needless redirects on small edits.
+func (s *Service) slugForTitle(ctx context.Context, id int64, title string) (*string, error) {
+	base := Slugify(title)
+	current, err := s.repo.GetPostSlug(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if hasSlugBase(current, base) {
+		return nil, nil
+	}
+
+	slug, err := s.uniqueSlug(ctx, id, base)
+	if err != nil {
+		return nil, err
+	}
+	return &slug, nil
+}
+
+// UpdatePostInput lists the post fields to change. Nil fields are left untouched; Tags replaces
+// the post's tags, so an empty list removes them all.
+type UpdatePostInput struct {
+	Title   *string
+	Content *string
+	Tags    *[]string
+}
+
+// UpdatePost changes a post on behalf of actorID. Only the author may do so unless editAny is set
+// for roles that are allowed to edit every post. A new title gives the post a new slug; the old
+// one keeps redirecting to it.
+func (s *Service) UpdatePost(ctx context.Context, actorID int64, editAny bool, id int64, input UpdatePostInput) (Post, error) {
+	if err := s.authorizeChange(ctx, actorID, editAny, id); err != nil {
+		return Post{}, err
+	}
+
+	params := UpdatePostParams{
+		Title:   input.Title,
+		Content: input.Content,
+	}
+	if input.Tags != nil {
+		tags, err := normalizeTags(*input.Tags)
+		if err != nil {
+			return Post{}, err
+		}
+		params.Tags = &tags
+	}
+	for attempt := 1; ; attempt++ {
+		if input.Title != nil {
+			slug, err := s.slugForTitle(ctx, id, *input.Title)
+			if err != nil {
+				return Post{}, err
+			}
+			params.Slug = slug
+		}
 
-	post, err := s.repo.CreatePost(ctx, CreatePostParams(input))
+		post, err := s.repo.UpdatePost(ctx, id, params)
+		if errors.Is(err, ErrSlugTaken) && attempt < slugAttempts {
+			continue
+		}
+		if err != nil {
+			return Post{}, err
+		}
+		return post, nil
+	}
+}
+
+// ChangeStatus moves a post to status on behalf of actorID under the same rules as UpdatePost.
+// Posts go from draft to published, from published to archived, and from either back to draft;
+// any other move fails with ErrInvalidStatus.
+func (s *Service) ChangeStatus(ctx context.Context, actorID int64, editAny bool, id int64, status Status) (Post, error) {
+	from, ok := statusSources[status]
+	if !ok {
+		return Post{}, ErrInvalidStatus
+	}
+
+	if err := s.authorizeChange(ctx, actorID, editAny, id); err != nil {
+		return Post{}, err
+	}
+
+	post, err := s.repo.TransitionPostStatus(ctx, id, status, from)
 	if err != nil {
-		return Post{}, fmt.Errorf("create post service : %w", err)
+		return Post{}, err
 	}
 	return post, nil
 }
 
+// SchedulePost sets when a draft goes live on behalf of actorID under the same rules as UpdatePost.
+// A zero publishAt cancels the schedule; otherwise it must lie in the future.
+func (s *Service) SchedulePost(ctx context.Context, actorID int64, editAny bool, id int64, publishAt time.Time) (Post, error) {
+	if !publishAt.IsZero() && !publishAt.After(time.Now()) {
+		return Post{}, ErrInvalidSchedule
+	}
+
+	if err := s.authorizeChange(ctx, actorID, editAny, id); err != nil {
+		return Post{}, err
+	}
+
+	post, err := s.repo.SchedulePost(ctx, id, publishAt)
+	if err != nil {
+		return Post{}, err
+	}
+	return post, nil
+}
+
+// publishBatchSize caps how many scheduled posts one query publishes, so a large backlog after
+// downtime is worked off in short transactions.
+const publishBatchSize = 100
+
+// PublishDuePosts publishes every scheduled draft whose time has come and returns how many it published.
+func (s *Service) PublishDuePosts(ctx context.Context) (int, error) {
+	var total int
+	for {
+		ids, err := s.repo.PublishDuePosts(ctx, publishBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("publish due posts service: %w", err)
+		}
+		total += len(ids)
+		if len(ids) < publishBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// RunScheduledPublisher publishes due posts every interval until ctx is cancelled. It starts with
+// an immediate run, which catches up on schedules missed while no replica was up. Running it on
+// several replicas at once is safe; each post is published exactly once.
+func (s *Service) RunScheduledPublisher(ctx context.Context, interval time.Duration) {
+	periodic.Run(ctx, interval, func(ctx context.Context) {
+		n, err := s.PublishDuePosts(ctx)
+		switch {
+		case err != nil:
+			log.Printf("publish scheduled posts: %v", err)
+		case n > 0:
+			log.Printf("published %d scheduled posts", n)
+		}
+	})
+}
+
+// DeletePost removes a post on behalf of actorID under the same rules as UpdatePost.
+func (s *Service) DeletePost(ctx context.Context, actorID int64, editAny bool, id int64) error {
+	if err := s.authorizeChange(ctx, actorID, editAny, id); err != nil {
+		return err
+	}
+	return s.repo.DeletePost(ctx, id)
+}
+
+// authorizeChange reports ErrPostNotFound for missing posts and ErrNotPostAuthor when actorID may
+// not change the post.
+func (s *Service) authorizeChange(ctx context.Context, actorID int64, editAny bool, id int64) error {
+	authorID, err := s.repo.GetPostAuthorID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if authorID != actorID && !editAny {
+		return ErrNotPostAuthor
+	}
+	return nil
+}
+
 const (
 	defaultPageLimit = 20
 	maxPageLimit     = 100
@@ -59,24 +271,157 @@ func NormalizeListInput(input ListPostsInput) ListPostsInput {
 	return input
 }
 
-// GetAllPosts returns paginated posts with their author usernames.
-func (s *Service) GetAllPosts(ctx context.Context, input ListPostsInput) ([]Row, error) {
+// TagFilter narrows a post listing to tagged posts. Posts match if they carry any of Tags, or all
+// of them when MatchAll is set. An empty filter matches every post.
+type TagFilter struct {
+	Tags     []string
+	MatchAll bool
+}
+
+// GetAllPosts returns paginated posts with their author usernames, narrowed by filter. viewerID is
+// the signed-in reader, or zero; their blocks and mutes are filtered out.
+func (s *Service) GetAllPosts(ctx context.Context, viewerID int64, filter TagFilter, input ListPostsInput) ([]Row, error) {
 	input = NormalizeListInput(input)
 
-	posts, err := s.repo.GetAllPosts(ctx, input.Limit, input.Offset)
+	if len(filter.Tags) == 0 {
+		posts, err := s.repo.GetAllPosts(ctx, viewerID, input.Limit, input.Offset)
+		if err != nil {
+			return nil, fmt.Errorf("get all posts service: %w", err)
+		}
+		return posts, nil
+	}
+
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.repo.GetPostsByTags(ctx, viewerID, tags, filter.MatchAll, input.Limit, input.Offset)
 	if err != nil {
 		return nil, fmt.Errorf("get all posts service: %w", err)
 	}
-
 	return posts, nil
 }
 
-// GetPostByID returns a single post with author username by ID.
-func (s *Service) GetPostByID(ctx context.Context, id int64) (Row, error) {
-	post, err := s.repo.GetPostByID(ctx, id)
+// ListTags returns a page of tags in use on published posts, most used first.
+func (s *Service) ListTags(ctx context.Context, input ListPostsInput) ([]Tag, error) {
+	input = NormalizeListInput(input)
+
+	tags, err := s.repo.ListTags(ctx, input.Limit, input.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("list tags service: %w", err)
+	}
+	return tags, nil
+}
+
+// GetPostByID returns a single post with author username by ID, hiding it from readers its author blocked.
+func (s *Service) GetPostByID(ctx context.Context, viewerID, id int64) (Row, error) {
+	post, err := s.repo.GetPostByID(ctx, viewerID, id)
 	if err != nil {
 		return Row{}, err
 	}
 
 	return post, nil
 }
+
+// GetPostBySlug returns a single post by its current slug under the same rules as GetPostByID.
+// A slug the post had before it was renamed yields a *MovedError carrying the current slug.
+func (s *Service) GetPostBySlug(ctx context.Context, viewerID int64, slug string) (Row, error) {
+	post, err := s.repo.GetPostBySlug(ctx, viewerID, slug)
+	if !errors.Is(err, ErrPostNotFound) {
+		return post, err
+	}
+
+	current, err := s.repo.GetSlugRedirect(ctx, viewerID, slug)
+	if err != nil {
+		return Row{}, err
+	}
+	return Row{}, &MovedError{Err: ErrPostMoved, Slug: current}
+}
+
+// GetPostsByAuthor returns a page of posts written by the user with the given username. Authors
+// see their drafts and archived posts here, everyone else only what is published. An author who
+// blocked viewerID is reported as not found; muting does not hide an author's own page.
+func (s *Service) GetPostsByAuthor(ctx context.Context, viewerID int64, username string, input ListPostsInput) ([]Row, error) {
+	input = NormalizeListInput(input)
+
+	authorID, err := s.repo.GetActiveAuthorID(ctx, viewerID, username)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.repo.GetPostsByAuthor(ctx, viewerID, authorID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("get posts by author service: %w", err)
+	}
+
+	return posts, nil
+}
+
+// FeedInput defines keyset pagination parameters for the home feed. Cursor is the NextCursor of
+// the previous page, or empty for the first page.
+type FeedInput struct {
+	Cursor string
+	Limit  int32
+}
+
+// Feed is one page of the home feed. NextCursor is empty on the last page.
+type Feed struct {
+	Posts      []Row
+	NextCursor string
+}
+
+// GetFeed returns a page of posts by the authors userID follows, newest first.
+func (s *Service) GetFeed(ctx context.Context, userID int64, input FeedInput) (Feed, error) {
+	limit := NormalizeListInput(ListPostsInput{Limit: input.Limit}).Limit
+
+	var cursor FeedCursor
+	if input.Cursor != "" {
+		var err error
+		if cursor, err = decodeFeedCursor(input.Cursor); err != nil {
+			return Feed{}, err
+		}
+	}
+
+	posts, err := s.repo.GetFeed(ctx, userID, cursor, limit)
+	if err != nil {
+		return Feed{}, fmt.Errorf("get feed service: %w", err)
+	}
+
+	feed := Feed{Posts: posts}
+	// A short page means there is nothing older left to fetch. Feed posts are all published, so
+	// each has a publish time.
+	if len(posts) == int(limit) {
+		last := posts[len(posts)-1]
+		if last.PublishedAt != nil {
+			feed.NextCursor = encodeFeedCursor(FeedCursor{PublishedAt: *last.PublishedAt, ID: last.ID})
+		}
+	}
+	return feed, nil
+}
+
+// encodeFeedCursor renders a cursor as an opaque URL-safe string.
+func encodeFeedCursor(c FeedCursor) string {
+	raw := c.PublishedAt.UTC().Format(time.RFC3339Nano) + "," + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeFeedCursor parses a cursor produced by encodeFeedCursor.
+func decodeFeedCursor(s string) (FeedCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return FeedCursor{}, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return FeedCursor{}, ErrInvalidCursor
+	}
+	publishedAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return FeedCursor{}, ErrInvalidCursor
+	}
+	postID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return FeedCursor{}, ErrInvalidCursor
+	}
+	return FeedCursor{PublishedAt: publishedAt, ID: postID}, nil
+}
@@ -0,0 +1,25 @@
+package post
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFeedCursorRoundTrip(t *testing.T) {
+	want := FeedCursor{PublishedAt: time.Date(2026, 3, 1, 12, 0, 0, 123_456_000, time.UTC), ID: 42}
+
+	got, err := decodeFeedCursor(encodeFeedCursor(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.PublishedAt.Equal(want.PublishedAt) || got.ID != want.ID {
+		t.Errorf("decodeFeedCursor(encodeFeedCursor(%+v)) = %+v", want, got)
+	}
+
+	for _, s := range []string{"not base64!", "bm8tY29tbWE", "bm90LWEtdGltZSw0Mg"} {
+		if _, err := decodeFeedCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeFeedCursor(%q) error = %v, want %v", s, err, ErrInvalidCursor)
+		}
+	}
+}
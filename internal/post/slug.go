@@ -0,0 +1,98 @@
+package post
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxSlugLength caps the slug derived from a title, leaving room for a collision suffix.
+const maxSlugLength = 80
+
+// fallbackSlug is used for titles without a single letter or digit that can be transliterated.
+const fallbackSlug = "post"
+
+// transliterations spells out letters that do not decompose into an ASCII base letter plus marks.
+// Accented Latin letters such as é, ş or ü need no entry; decomposition strips their marks. A few
+// letters, like Cyrillic й, are listed whole because their base letter reads differently.
+var transliterations = map[rune]string{
+	// Latin
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th",
+	'ı': "i", 'ħ': "h", 'ŧ': "t", 'ŋ': "ng", 'ĸ': "k",
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th",
+	'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p",
+	'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps",
+	'ω': "o",
+}
+
+// Slugify turns a title into a lowercase ASCII slug of letters, digits and single hyphens,
+// transliterating other scripts where it can. It never returns an empty string.
+func Slugify(title string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	write := func(s string) {
+		if s == "" {
+			return
+		}
+		if pendingHyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pendingHyphen = false
+		b.WriteString(s)
+	}
+
+	for _, r := range strings.ToLower(title) {
+		if ascii, ok := transliterations[r]; ok {
+			write(ascii)
+			continue
+		}
+		// Compatibility decomposition also unfolds ligatures and full-width forms, and splits
+		// accented letters of other scripts, such as Greek ά, into a letter the table knows.
+		for _, d := range norm.NFKD.String(string(r)) {
+			ascii, ok := transliterations[d]
+			switch {
+			case ok:
+				write(ascii)
+			case d >= 'a' && d <= 'z', d >= '0' && d <= '9':
+				write(string(d))
+			case d >= 'A' && d <= 'Z':
+				write(string(unicode.ToLower(d)))
+			case unicode.Is(unicode.Mn, d):
+				// Accents and other combining marks are dropped.
+			default:
+				pendingHyphen = true
+			}
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		// Cut at a word boundary when there is one.
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	if slug == "" {
+		return fallbackSlug
+	}
+	return slug
+}
+
+// slugSuffix matches the "-N" a colliding slug is given.
+var slugSuffix = regexp.MustCompile(`^-[0-9]+$`)
+
+// hasSlugBase reports whether slug is base itself or base with a collision suffix.
+func hasSlugBase(slug, base string) bool {
+	rest, ok := strings.CutPrefix(slug, base)
+	return ok && (rest == "" || slugSuffix.MatchString(rest))
+}
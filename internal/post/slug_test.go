@@ -0,0 +1,65 @@
+package post
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Hello, World!", "hello-world"},
+		{"  Go 1.25 is out  ", "go-1-25-is-out"},
+		{"Crème brûlée", "creme-brulee"},
+		{"Straße und Öl", "strasse-und-ol"},
+		{"İstanbul'da çay içmek", "istanbul-da-cay-icmek"},
+		{"Привет, мир", "privet-mir"},
+		{"Καλημέρα κόσμε", "kalimera-kosme"},
+		{"ﬁnal ｗｏｒｄ", "final-word"},
+		{"---", fallbackSlug},
+		{"", fallbackSlug},
+		{"日本語", fallbackSlug},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := Slugify(tt.title); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugifyCapsLengthAtWordBoundary(t *testing.T) {
+	title := strings.Repeat("word ", 30)
+	got := Slugify(title)
+	if len(got) > maxSlugLength {
+		t.Fatalf("len(Slugify) = %d, want at most %d", len(got), maxSlugLength)
+	}
+	if strings.HasSuffix(got, "-") || !strings.HasSuffix(got, "word") {
+		t.Errorf("Slugify cut mid-word: %q", got)
+	}
+}
+
+func TestHasSlugBase(t *testing.T) {
+	tests := []struct {
+		slug, base string
+		want       bool
+	}{
+		{"go", "go", true},
+		{"go-2", "go", true},
+		{"go-10", "go", true},
+		{"go-tips", "go", false},
+		{"go-", "go", false},
+		{"go-2-3", "go", false},
+		{"golang", "go", false},
+		{"g", "go", false},
+		{"go-tips-2", "go-tips", true},
+	}
+	for _, tt := range tests {
+		if got := hasSlugBase(tt.slug, tt.base); got != tt.want {
+			t.Errorf("hasSlugBase(%q, %q) = %v, want %v", tt.slug, tt.base, got, tt.want)
+		}
+	}
+}
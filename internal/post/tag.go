@@ -0,0 +1,33 @@
+package post
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// maxPostTags caps how many tags one post can carry, and how many a listing can filter by.
+const maxPostTags = 10
+
+// tagPattern mirrors the chk_tags_name constraint: up to 32 lowercase letters, digits and hyphens.
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// normalizeTags lowercases and trims tags, drops duplicates and sorts them. It returns
+// ErrInvalidTag if any tag breaks the naming rules and ErrTooManyTags past maxPostTags.
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagPattern.MatchString(tag) {
+			return nil, ErrInvalidTag
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+
+	if len(normalized) > maxPostTags {
+		return nil, ErrTooManyTags
+	}
+	return normalized, nil
+}
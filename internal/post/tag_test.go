@@ -0,0 +1,56 @@
+package post
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tooMany := make([]string, maxPostTags+1)
+	for i := range tooMany {
+		tooMany[i] = "tag-" + strconv.Itoa(i)
+	}
+	atCap := append(tooMany[:maxPostTags:maxPostTags], " TAG-0 ")
+
+	tests := []struct {
+		name    string
+		tags    []string
+		want    []string
+		wantErr error
+	}{
+		{name: "nil", tags: nil, want: []string{}},
+		{name: "sorted and lowercased", tags: []string{"Rust", " go ", "web-dev"}, want: []string{"go", "rust", "web-dev"}},
+		{name: "duplicates after normalizing", tags: []string{"Go", "go", " GO"}, want: []string{"go"}},
+		{name: "longest allowed", tags: []string{strings.Repeat("a", 32)}, want: []string{strings.Repeat("a", 32)}},
+		{name: "duplicates do not count toward the cap", tags: atCap, want: sortedCopy(tooMany[:maxPostTags])},
+		{name: "empty", tags: []string{"go", "  "}, wantErr: ErrInvalidTag},
+		{name: "leading hyphen", tags: []string{"-go"}, wantErr: ErrInvalidTag},
+		{name: "space inside", tags: []string{"web dev"}, wantErr: ErrInvalidTag},
+		{name: "non-ascii", tags: []string{"café"}, wantErr: ErrInvalidTag},
+		{name: "too long", tags: []string{strings.Repeat("a", 33)}, wantErr: ErrInvalidTag},
+		{name: "too many", tags: tooMany, wantErr: ErrTooManyTags},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTags(tt.tags)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("normalizeTags(%q) error = %v, want %v", tt.tags, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got == nil || !slices.Equal(got, tt.want) {
+				t.Errorf("normalizeTags(%q) = %#v, want %#v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func sortedCopy(s []string) []string {
+	c := slices.Clone(s)
+	slices.Sort(c)
+	return c
+}
@@ -8,12 +8,150 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AccountDeletion struct {
+	UserID           int64
+	RequestedAt      pgtype.Timestamptz
+	PurgeAfter       pgtype.Timestamptz
+	RestoreTokenHash pgtype.Text
+}
+
+type AuthEvent struct {
+	ID        int64
+	UserID    pgtype.Int8
+	EventType string
+	Ip        string
+	UserAgent string
+	RequestID string
+	Metadata  []byte
+	CreatedAt pgtype.Timestamptz
+}
+
+type AuthThrottle struct {
+	Key           string
+	Failures      int32
+	LastFailureAt pgtype.Timestamptz
+	LockedUntil   pgtype.Timestamptz
+}
+
+type Block struct {
+	BlockerID int64
+	BlockedID int64
+	CreatedAt pgtype.Timestamptz
+}
+
+type Follow struct {
+	FollowerID int64
+	FolloweeID int64
+	CreatedAt  pgtype.Timestamptz
+}
+
+type Invite struct {
+	ID         int64
+	CreatedBy  int64
+	CodePrefix string
+	CodeHash   string
+	Email      pgtype.Text
+	MaxUses    int32
+	UseCount   int32
+	ExpiresAt  pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+	CreatedAt  pgtype.Timestamptz
+}
+
+type MagicLinkToken struct {
+	ID         int64
+	UserID     int64
+	TokenHash  string
+	ClientHash string
+	ExpiresAt  pgtype.Timestamptz
+	UsedAt     pgtype.Timestamptz
+	CreatedAt  pgtype.Timestamptz
+}
+
+type Mute struct {
+	MuterID   int64
+	MutedID   int64
+	CreatedAt pgtype.Timestamptz
+}
+
+type PasswordResetToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	ExpiresAt pgtype.Timestamptz
+	UsedAt    pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type PersonalAccessToken struct {
+	ID          int64
+	UserID      int64
+	Name        string
+	TokenPrefix string
+	TokenHash   string
+	Scopes      []string
+	ExpiresAt   pgtype.Timestamptz
+	LastUsedAt  pgtype.Timestamptz
+	RevokedAt   pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+}
+
 type Post struct {
+	ID          int64
+	AuthorID    int64
+	Title       string
+	Content     string
+	UpdatedAt   pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	Status      string
+	PublishedAt pgtype.Timestamptz
+	PublishAt   pgtype.Timestamptz
+	Slug        string
+}
+
+type PostSlugHistory struct {
+	Slug      string
+	PostID    int64
+	CreatedAt pgtype.Timestamptz
+}
+
+type PostTag struct {
+	PostID int64
+	TagID  int64
+}
+
+type RecoveryCode struct {
+	ID        int64
+	UserID    int64
+	CodeHash  string
+	UsedAt    pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type RefreshToken struct {
+	ID        int64
+	UserID    int64
+	FamilyID  pgtype.UUID
+	TokenHash string
+	ExpiresAt pgtype.Timestamptz
+	RotatedAt pgtype.Timestamptz
+	RevokedAt pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type Session struct {
+	ID         pgtype.UUID
+	UserID     int64
+	UserAgent  string
+	Ip         string
+	CreatedAt  pgtype.Timestamptz
+	LastSeenAt pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+}
+
+type Tag struct {
 	ID        int64
-	AuthorID  int64
-	Title     string
-	Content   string
-	UpdatedAt pgtype.Timestamptz
+	Name      string
 	CreatedAt pgtype.Timestamptz
 }
 
@@ -26,4 +164,14 @@ type User struct {
 	TokenInvalidBefore pgtype.Timestamptz
 	CreatedAt          pgtype.Timestamptz
 	UpdatedAt          pgtype.Timestamptz
+	EmailVerifiedAt    pgtype.Timestamptz
+	VerificationSentAt pgtype.Timestamptz
+	TotpSecret         pgtype.Text
+	TotpEnabledAt      pgtype.Timestamptz
+	TotpLastStep       int64
+	Role               string
+	DisplayName        string
+	Bio                string
+	Website            string
+	SocialLinks        []byte
 }
@@ -12,19 +12,25 @@ import (
 )
 
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (author_id, title, content)
-VALUES ($1, $2, $3)
-RETURNING id, author_id, title, content, updated_at, created_at
+INSERT INTO posts (author_id, title, content, slug)
+VALUES ($1, $2, $3, $4)
+RETURNING id, author_id, title, content, updated_at, created_at, status, published_at, publish_at, slug
 `
 
 type CreatePostParams struct {
 	AuthorID int64
 	Title    string
 	Content  string
+	Slug     string
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRow(ctx, createPost, arg.AuthorID, arg.Title, arg.Content)
+	row := q.db.QueryRow(ctx, createPost,
+		arg.AuthorID,
+		arg.Title,
+		arg.Content,
+		arg.Slug,
+	)
 	var i Post
 	err := row.Scan(
 		&i.ID,
@@ -33,33 +39,111 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Content,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.Status,
+		&i.PublishedAt,
+		&i.PublishAt,
+		&i.Slug,
 	)
 	return i, err
 }
 
+const deletePost = `-- name: DeletePost :execrows
+DELETE FROM posts
+WHERE id = $1
+`
+
+func (q *Queries) DeletePost(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePost, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deletePostSlugHistory = `-- name: DeletePostSlugHistory :exec
+DELETE FROM post_slug_history
+WHERE slug = $1 AND post_id = $2
+`
+
+type DeletePostSlugHistoryParams struct {
+	Slug   string
+	PostID int64
+}
+
+func (q *Queries) DeletePostSlugHistory(ctx context.Context, arg DeletePostSlugHistoryParams) error {
+	_, err := q.db.Exec(ctx, deletePostSlugHistory, arg.Slug, arg.PostID)
+	return err
+}
+
+const getActiveAuthorID = `-- name: GetActiveAuthorID :one
+SELECT u.id FROM users u
+WHERE u.username = $1 AND u.is_active = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = u.id AND b.blocked_id = $2
+  )
+`
+
+type GetActiveAuthorIDParams struct {
+	Username string
+	ViewerID int64
+}
+
+func (q *Queries) GetActiveAuthorID(ctx context.Context, arg GetActiveAuthorIDParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getActiveAuthorID, arg.Username, arg.ViewerID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const getAllPosts = `-- name: GetAllPosts :many
-SELECT p.id, p.author_id, p.title, p.content, p.updated_at, p.created_at, u.username FROM posts p JOIN users u ON u.id = p.author_id
-ORDER BY p.created_at DESC
-LIMIT $1 OFFSET $2
+SELECT p.id, p.author_id, p.title, p.content, p.updated_at, p.created_at, p.status, p.published_at, p.publish_at, p.slug, u.username,
+  COALESCE((
+    SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+    WHERE pt.post_id = p.id
+  ), '{}')::text[] AS tags
+FROM posts p JOIN users u ON u.id = p.author_id
+WHERE (p.status = 'published' OR p.author_id = $1)
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE (b.blocker_id = p.author_id AND b.blocked_id = $1)
+       OR (b.blocker_id = $1 AND b.blocked_id = p.author_id)
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM mutes m
+    WHERE m.muter_id = $1 AND m.muted_id = p.author_id
+  )
+ORDER BY p.published_at DESC, p.id DESC
+LIMIT $2 OFFSET $3
 `
 
 type GetAllPostsParams struct {
-	Limit  int32
-	Offset int32
+	ViewerID   int64
+	PageLimit  int32
+	PageOffset int32
 }
 
 type GetAllPostsRow struct {
-	ID        int64
-	AuthorID  int64
-	Title     string
-	Content   string
-	UpdatedAt pgtype.Timestamptz
-	CreatedAt pgtype.Timestamptz
-	Username  string
+	ID          int64
+	AuthorID    int64
+	Title       string
+	Content     string
+	UpdatedAt   pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	Status      string
+	PublishedAt pgtype.Timestamptz
+	PublishAt   pgtype.Timestamptz
+	Slug        string
+	Username    string
+	Tags        []string
 }
 
+// Lists published posts and the viewer's own drafts and archived posts, leaving out authors the
+// viewer blocked, muted or was blocked by. Anonymous readers pass a viewer ID of 0, which matches
+// no author or relation.
+// Posts are listed newest published first; drafts have no publish time and sort ahead of them.
 func (q *Queries) GetAllPosts(ctx context.Context, arg GetAllPostsParams) ([]GetAllPostsRow, error) {
-	rows, err := q.db.Query(ctx, getAllPosts, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, getAllPosts, arg.ViewerID, arg.PageLimit, arg.PageOffset)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +158,97 @@ func (q *Queries) GetAllPosts(ctx context.Context, arg GetAllPostsParams) ([]Get
 			&i.Content,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.Status,
+			&i.PublishedAt,
+			&i.PublishAt,
+			&i.Slug,
+			&i.Username,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeed = `-- name: GetFeed :many
+SELECT p.id, p.author_id, p.title, p.content, p.updated_at, p.created_at, p.status, p.published_at, p.slug, u.username,
+  COALESCE((
+    SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+    WHERE pt.post_id = p.id
+  ), '{}')::text[] AS tags
+FROM follows f
+JOIN users u ON u.id = f.followee_id AND u.is_active = TRUE
+CROSS JOIN LATERAL (
+  SELECT ap.id, ap.author_id, ap.title, ap.content, ap.updated_at, ap.created_at, ap.status, ap.published_at, ap.slug FROM posts ap
+  WHERE ap.author_id = f.followee_id
+    AND ap.status = 'published'
+    AND (ap.published_at, ap.id) < ($1::timestamptz, $2::bigint)
+  ORDER BY ap.published_at DESC, ap.id DESC
+  LIMIT $3::int
+) p
+WHERE f.follower_id = $4
+  AND NOT EXISTS (
+    SELECT 1 FROM mutes m
+    WHERE m.muter_id = $4 AND m.muted_id = f.followee_id
+  )
+ORDER BY p.published_at DESC, p.id DESC
+LIMIT $3::int
+`
+
+type GetFeedParams struct {
+	BeforePublishedAt pgtype.Timestamptz
+	BeforeID          int64
+	PageSize          int32
+	FollowerID        int64
+}
+
+type GetFeedRow struct {
+	ID          int64
+	AuthorID    int64
+	Title       string
+	Content     string
+	UpdatedAt   pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	Status      string
+	PublishedAt pgtype.Timestamptz
+	Slug        string
+	Username    string
+	Tags        []string
+}
+
+// Takes at most one page from each followed author through the (author_id, published_at, id)
+// index and merges those, so the cost grows with the number of follows, not with their history.
+func (q *Queries) GetFeed(ctx context.Context, arg GetFeedParams) ([]GetFeedRow, error) {
+	rows, err := q.db.Query(ctx, getFeed,
+		arg.BeforePublishedAt,
+		arg.BeforeID,
+		arg.PageSize,
+		arg.FollowerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedRow
+	for rows.Next() {
+		var i GetFeedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AuthorID,
+			&i.Title,
+			&i.Content,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Status,
+			&i.PublishedAt,
+			&i.Slug,
 			&i.Username,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -86,23 +260,56 @@ func (q *Queries) GetAllPosts(ctx context.Context, arg GetAllPostsParams) ([]Get
 	return items, nil
 }
 
+const getPostAuthorID = `-- name: GetPostAuthorID :one
+SELECT author_id FROM posts
+WHERE id = $1
+`
+
+func (q *Queries) GetPostAuthorID(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRow(ctx, getPostAuthorID, id)
+	var author_id int64
+	err := row.Scan(&author_id)
+	return author_id, err
+}
+
 const getPostById = `-- name: GetPostById :one
-SELECT p.id, p.author_id, p.title, p.content, p.updated_at, p.created_at, u.username FROM posts p JOIN users u ON u.id = p.author_id
+SELECT p.id, p.author_id, p.title, p.content, p.updated_at, p.created_at, p.status, p.published_at, p.publish_at, p.slug, u.username,
+  COALESCE((
+    SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+    WHERE pt.post_id = p.id
+  ), '{}')::text[] AS tags
+FROM posts p JOIN users u ON u.id = p.author_id
 WHERE p.id = $1
+  AND (p.status <> 'draft' OR p.author_id = $2)
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = p.author_id AND b.blocked_id = $2
+  )
 `
 
+type GetPostByIdParams struct {
+	ID       int64
+	ViewerID int64
+}
+
 type GetPostByIdRow struct {
-	ID        int64
-	AuthorID  int64
-	Title     string
-	Content   string
-	UpdatedAt pgtype.Timestamptz
-	CreatedAt pgtype.Timestamptz
-	Username  string
+	ID          int64
+	AuthorID    int64
+	Title       string
+	Content     string
+	UpdatedAt   pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	Status      string
+	PublishedAt pgtype.Timestamptz
+	PublishAt   pgtype.Timestamptz
+	Slug        string
+	Username    string
+	Tags        []string
 }
 
-func (q *Queries) GetPostById(ctx context.Context, id int64) (GetPostByIdRow, error) {
-	row := q.db.QueryRow(ctx, getPostById, id)
+// Drafts are only visible to their author; archived posts stay reachable by their link.
+func (q *Queries) GetPostById(ctx context.Context, arg GetPostByIdParams) (GetPostByIdRow, error) {
+	row := q.db.QueryRow(ctx, getPostById, arg.ID, arg.ViewerID)
 	var i GetPostByIdRow
 	err := row.Scan(
 		&i.ID,
@@ -111,7 +318,515 @@ func (q *Queries) GetPostById(ctx context.Context, id int64) (GetPostByIdRow, er
 		&i.Content,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.Status,
+		&i.PublishedAt,
+		&i.PublishAt,
+		&i.Slug,
+		&i.Username,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getPostBySlug = `-- name: GetPostBySlug :one
+SELECT p.id, p.author_id, p.title, p.content, p.updated_at, p.created_at, p.status, p.published_at, p.publish_at, p.slug, u.username,
+  COALESCE((
+    SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+    WHERE pt.post_id = p.id
+  ), '{}')::text[] AS tags
+FROM posts p JOIN users u ON u.id = p.author_id
+WHERE p.slug = $1
+  AND (p.status <> 'draft' OR p.author_id = $2)
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = p.author_id AND b.blocked_id = $2
+  )
+`
+
+type GetPostBySlugParams struct {
+	Slug     string
+	ViewerID int64
+}
+
+type GetPostBySlugRow struct {
+	ID          int64
+	AuthorID    int64
+	Title       string
+	Content     string
+	UpdatedAt   pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	Status      string
+	PublishedAt pgtype.Timestamptz
+	PublishAt   pgtype.Timestamptz
+	Slug        string
+	Username    string
+	Tags        []string
+}
+
+// Same visibility rules as GetPostById.
+func (q *Queries) GetPostBySlug(ctx context.Context, arg GetPostBySlugParams) (GetPostBySlugRow, error) {
+	row := q.db.QueryRow(ctx, getPostBySlug, arg.Slug, arg.ViewerID)
+	var i GetPostBySlugRow
+	err := row.Scan(
+		&i.ID,
+		&i.AuthorID,
+		&i.Title,
+		&i.Content,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.Status,
+		&i.PublishedAt,
+		&i.PublishAt,
+		&i.Slug,
 		&i.Username,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getPostSlug = `-- name: GetPostSlug :one
+SELECT slug FROM posts
+WHERE id = $1
+`
+
+func (q *Queries) GetPostSlug(ctx context.Context, id int64) (string, error) {
+	row := q.db.QueryRow(ctx, getPostSlug, id)
+	var slug string
+	err := row.Scan(&slug)
+	return slug, err
+}
+
+const getPostsByAuthor = `-- name: GetPostsByAuthor :many
+SELECT p.id, p.author_id, p.title, p.content, p.updated_at, p.created_at, p.status, p.published_at, p.publish_at, p.slug, u.username,
+  COALESCE((
+    SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+    WHERE pt.post_id = p.id
+  ), '{}')::text[] AS tags
+FROM posts p JOIN users u ON u.id = p.author_id
+WHERE p.author_id = $1
+  AND (p.status = 'published' OR p.author_id = $2)
+ORDER BY p.published_at DESC, p.id DESC
+LIMIT $3 OFFSET $4
+`
+
+type GetPostsByAuthorParams struct {
+	AuthorID   int64
+	ViewerID   int64
+	PageLimit  int32
+	PageOffset int32
+}
+
+type GetPostsByAuthorRow struct {
+	ID          int64
+	AuthorID    int64
+	Title       string
+	Content     string
+	UpdatedAt   pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	Status      string
+	PublishedAt pgtype.Timestamptz
+	PublishAt   pgtype.Timestamptz
+	Slug        string
+	Username    string
+	Tags        []string
+}
+
+// Other readers only see published posts; the author also sees their drafts and archive.
+// Ordered like GetAllPosts.
+func (q *Queries) GetPostsByAuthor(ctx context.Context, arg GetPostsByAuthorParams) ([]GetPostsByAuthorRow, error) {
+	rows, err := q.db.Query(ctx, getPostsByAuthor,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsByAuthorRow
+	for rows.Next() {
+		var i GetPostsByAuthorRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AuthorID,
+			&i.Title,
+			&i.Content,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Status,
+			&i.PublishedAt,
+			&i.PublishAt,
+			&i.Slug,
+			&i.Username,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsByTags = `-- name: GetPostsByTags :many
+WITH tagged AS (
+  SELECT pt.post_id FROM tags t JOIN post_tags pt ON pt.tag_id = t.id
+  WHERE t.name = ANY($1::text[])
+  GROUP BY pt.post_id
+  HAVING NOT $2::boolean OR count(*) = cardinality($1::text[])
+)
+SELECT p.id, p.author_id, p.title, p.content, p.updated_at, p.created_at, p.status, p.published_at, p.publish_at, p.slug, u.username,
+  COALESCE((
+    SELECT array_agg(t.name ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+    WHERE pt.post_id = p.id
+  ), '{}')::text[] AS tags
+FROM tagged
+JOIN posts p ON p.id = tagged.post_id
+JOIN users u ON u.id = p.author_id
+WHERE (p.status = 'published' OR p.author_id = $3)
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE (b.blocker_id = p.author_id AND b.blocked_id = $3)
+       OR (b.blocker_id = $3 AND b.blocked_id = p.author_id)
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM mutes m
+    WHERE m.muter_id = $3 AND m.muted_id = p.author_id
+  )
+ORDER BY p.published_at DESC, p.id DESC
+LIMIT $4 OFFSET $5
+`
+
+type GetPostsByTagsParams struct {
+	Tags       []string
+	MatchAll   bool
+	ViewerID   int64
+	PageLimit  int32
+	PageOffset int32
+}
+
+type GetPostsByTagsRow struct {
+	ID          int64
+	AuthorID    int64
+	Title       string
+	Content     string
+	UpdatedAt   pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	Status      string
+	PublishedAt pgtype.Timestamptz
+	PublishAt   pgtype.Timestamptz
+	Slug        string
+	Username    string
+	Tags        []string
+}
+
+// GetAllPosts narrowed to posts tagged with any of @tags, or with all of them when @match_all is
+// set. Matching starts from the tags and reads post_tags by tag, so only posts carrying one of them
+// are looked at. @tags holds no duplicates, so a post carries all of them when it matches as many
+// rows as there are tags.
+func (q *Queries) GetPostsByTags(ctx context.Context, arg GetPostsByTagsParams) ([]GetPostsByTagsRow, error) {
+	rows, err := q.db.Query(ctx, getPostsByTags,
+		arg.Tags,
+		arg.MatchAll,
+		arg.ViewerID,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsByTagsRow
+	for rows.Next() {
+		var i GetPostsByTagsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AuthorID,
+			&i.Title,
+			&i.Content,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Status,
+			&i.PublishedAt,
+			&i.PublishAt,
+			&i.Slug,
+			&i.Username,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSlugRedirect = `-- name: GetSlugRedirect :one
+SELECT p.slug FROM post_slug_history h JOIN posts p ON p.id = h.post_id
+WHERE h.slug = $1
+  AND (p.status <> 'draft' OR p.author_id = $2)
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = p.author_id AND b.blocked_id = $2
+  )
+`
+
+type GetSlugRedirectParams struct {
+	Slug     string
+	ViewerID int64
+}
+
+// Resolves a slug a post used to have to the post's current slug, as long as the viewer may see the post.
+func (q *Queries) GetSlugRedirect(ctx context.Context, arg GetSlugRedirectParams) (string, error) {
+	row := q.db.QueryRow(ctx, getSlugRedirect, arg.Slug, arg.ViewerID)
+	var slug string
+	err := row.Scan(&slug)
+	return slug, err
+}
+
+const listAuthorPosts = `-- name: ListAuthorPosts :many
+SELECT id, author_id, title, content, updated_at, created_at, status, published_at, publish_at, slug FROM posts
+WHERE author_id = $1
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListAuthorPosts(ctx context.Context, authorID int64) ([]Post, error) {
+	rows, err := q.db.Query(ctx, listAuthorPosts, authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.AuthorID,
+			&i.Title,
+			&i.Content,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Status,
+			&i.PublishedAt,
+			&i.PublishAt,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTakenSlugs = `-- name: ListTakenSlugs :many
+SELECT slug FROM posts
+WHERE slug LIKE $1::text || '%' AND slug ~ ('^' || $1::text || '(-[0-9]+)?$') AND id <> $2
+UNION
+SELECT slug FROM post_slug_history
+WHERE slug LIKE $1::text || '%' AND slug ~ ('^' || $1::text || '(-[0-9]+)?$') AND post_id <> $2
+`
+
+type ListTakenSlugsParams struct {
+	Base   string
+	PostID int64
+}
+
+// Lists the slugs made of @base and an optional -N suffix that other posts use now or used before,
+// so a new slug neither collides with a post nor steals another post's old links. A post may take
+// back its own. Slugs hold no LIKE or regex metacharacters, so the prefix match can use the
+// text_pattern_ops indexes and the regex only drops longer slugs such as go-tips for go.
+func (q *Queries) ListTakenSlugs(ctx context.Context, arg ListTakenSlugsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, listTakenSlugs, arg.Base, arg.PostID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		items = append(items, slug)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const publishDuePosts = `-- name: PublishDuePosts :many
+WITH due AS (
+  SELECT id FROM posts
+  WHERE status = 'draft' AND publish_at <= now()
+  ORDER BY publish_at
+  LIMIT $1
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE posts p SET
+  status = 'published',
+  published_at = now(),
+  publish_at = NULL,
+  updated_at = now()
+FROM due
+WHERE p.id = due.id
+RETURNING p.id
+`
+
+// Publishes up to @batch_size drafts whose publish time has passed, including ones missed while
+// no scheduler was running. SKIP LOCKED lets several replicas run this at once without waiting
+// on or publishing the same rows.
+func (q *Queries) PublishDuePosts(ctx context.Context, batchSize int32) ([]int64, error) {
+	rows, err := q.db.Query(ctx, publishDuePosts, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const savePostSlugHistory = `-- name: SavePostSlugHistory :exec
+INSERT INTO post_slug_history (slug, post_id)
+SELECT slug, id FROM posts
+WHERE id = $1
+ON CONFLICT (slug) DO NOTHING
+`
+
+// Keeps the post's current slug so links to it keep working after the slug changes.
+func (q *Queries) SavePostSlugHistory(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, savePostSlugHistory, id)
+	return err
+}
+
+const schedulePost = `-- name: SchedulePost :one
+UPDATE posts SET
+  publish_at = $1,
+  updated_at = now()
+WHERE id = $2 AND status = 'draft'
+RETURNING id, author_id, title, content, updated_at, created_at, status, published_at, publish_at, slug
+`
+
+type SchedulePostParams struct {
+	PublishAt pgtype.Timestamptz
+	ID        int64
+}
+
+// Sets or clears the time a draft goes live. Posts that are not drafts are left alone.
+func (q *Queries) SchedulePost(ctx context.Context, arg SchedulePostParams) (Post, error) {
+	row := q.db.QueryRow(ctx, schedulePost, arg.PublishAt, arg.ID)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.AuthorID,
+		&i.Title,
+		&i.Content,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.Status,
+		&i.PublishedAt,
+		&i.PublishAt,
+		&i.Slug,
+	)
+	return i, err
+}
+
+const transitionPostStatus = `-- name: TransitionPostStatus :one
+UPDATE posts SET
+  status = $1,
+  published_at = CASE
+    WHEN $1 = 'published' THEN now()
+    WHEN $1 = 'draft' THEN NULL
+    ELSE published_at
+  END,
+  publish_at = NULL,
+  updated_at = now()
+WHERE id = $2 AND status = ANY($3::text[])
+RETURNING id, author_id, title, content, updated_at, created_at, status, published_at, publish_at, slug
+`
+
+type TransitionPostStatusParams struct {
+	ToStatus     string
+	ID           int64
+	FromStatuses []string
+}
+
+// Moves a post to @to_status only while it is in one of @from_statuses, so two concurrent
+// transitions cannot skip a state. Publishing stamps published_at and returning to draft clears it;
+// any move drops a pending schedule.
+func (q *Queries) TransitionPostStatus(ctx context.Context, arg TransitionPostStatusParams) (Post, error) {
+	row := q.db.QueryRow(ctx, transitionPostStatus, arg.ToStatus, arg.ID, arg.FromStatuses)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.AuthorID,
+		&i.Title,
+		&i.Content,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.Status,
+		&i.PublishedAt,
+		&i.PublishAt,
+		&i.Slug,
+	)
+	return i, err
+}
+
+const updatePost = `-- name: UpdatePost :one
+UPDATE posts SET
+  title = COALESCE($1, title),
+  content = COALESCE($2, content),
+  slug = COALESCE($3, slug),
+  updated_at = now()
+WHERE id = $4
+RETURNING id, author_id, title, content, updated_at, created_at, status, published_at, publish_at, slug
+`
+
+type UpdatePostParams struct {
+	Title   pgtype.Text
+	Content pgtype.Text
+	Slug    pgtype.Text
+	ID      int64
+}
+
+// Only the fields passed as non-NULL are changed.
+func (q *Queries) UpdatePost(ctx context.Context, arg UpdatePostParams) (Post, error) {
+	row := q.db.QueryRow(ctx, updatePost,
+		arg.Title,
+		arg.Content,
+		arg.Slug,
+		arg.ID,
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.AuthorID,
+		&i.Title,
+		&i.Content,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.Status,
+		&i.PublishedAt,
+		&i.PublishAt,
+		&i.Slug,
 	)
 	return i, err
 }
@@ -7,12 +7,14 @@ package sqlc
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (email, username, password_hash)
-VALUES ($1, $2, $3)
-RETURNING id, email, username, password_hash, is_active, token_invalid_before, created_at, updated_at
+INSERT INTO users (email, username, password_hash, is_active)
+VALUES ($1, $2, $3, FALSE)
+RETURNING id, email, username, password_hash, is_active, token_invalid_before, created_at, updated_at, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, role, display_name, bio, website, social_links
 `
 
 type CreateUserParams struct {
@@ -33,10 +35,175 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.TokenInvalidBefore,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.Role,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Website,
+		&i.SocialLinks,
+	)
+	return i, err
+}
+
+const deactivateUser = `-- name: DeactivateUser :execrows
+UPDATE users SET is_active = FALSE, updated_at = now()
+WHERE id = $1 AND is_active = TRUE
+`
+
+func (q *Queries) DeactivateUser(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deactivateUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const disableTOTP = `-- name: DisableTOTP :exec
+UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0, updated_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) DisableTOTP(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, disableTOTP, id)
+	return err
+}
+
+const enableTOTP = `-- name: EnableTOTP :execrows
+UPDATE users SET totp_enabled_at = now(), totp_last_step = $2, updated_at = now()
+WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+`
+
+type EnableTOTPParams struct {
+	ID           int64
+	TotpLastStep int64
+}
+
+func (q *Queries) EnableTOTP(ctx context.Context, arg EnableTOTPParams) (int64, error) {
+	result, err := q.db.Exec(ctx, enableTOTP, arg.ID, arg.TotpLastStep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getAnyByEmail = `-- name: GetAnyByEmail :one
+SELECT
+  u.id,
+  u.email,
+  u.username,
+  u.password_hash,
+  u.is_active,
+  u.token_invalid_before,
+  u.created_at,
+  u.updated_at,
+  u.email_verified_at,
+  u.verification_sent_at,
+  u.totp_secret,
+  u.totp_enabled_at,
+  u.totp_last_step,
+  u.role,
+  u.display_name,
+  u.bio,
+  u.website,
+  u.social_links
+FROM users u
+WHERE u.email = $1
+`
+
+// Unlike GetByEmail this also returns inactive accounts so sign-in can explain why they are blocked.
+func (q *Queries) GetAnyByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getAnyByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.IsActive,
+		&i.TokenInvalidBefore,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.Role,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Website,
+		&i.SocialLinks,
 	)
 	return i, err
 }
 
+const getAnyByID = `-- name: GetAnyByID :one
+SELECT
+  u.id,
+  u.email,
+  u.username,
+  u.password_hash,
+  u.is_active,
+  u.token_invalid_before,
+  u.created_at,
+  u.updated_at,
+  u.email_verified_at,
+  u.verification_sent_at,
+  u.totp_secret,
+  u.totp_enabled_at,
+  u.totp_last_step,
+  u.role,
+  u.display_name,
+  u.bio,
+  u.website,
+  u.social_links
+FROM users u
+WHERE u.id = $1
+`
+
+func (q *Queries) GetAnyByID(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRow(ctx, getAnyByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.IsActive,
+		&i.TokenInvalidBefore,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.Role,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Website,
+		&i.SocialLinks,
+	)
+	return i, err
+}
+
+const getAnyIDByUsername = `-- name: GetAnyIDByUsername :one
+SELECT u.id FROM users u
+WHERE u.username = $1
+`
+
+// Resolves a username whether or not the account is still active.
+func (q *Queries) GetAnyIDByUsername(ctx context.Context, username string) (int64, error) {
+	row := q.db.QueryRow(ctx, getAnyIDByUsername, username)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const getByEmail = `-- name: GetByEmail :one
 SELECT
   u.id,
@@ -46,7 +213,17 @@ SELECT
   u.is_active,
   u.token_invalid_before,
   u.created_at,
-  u.updated_at
+  u.updated_at,
+  u.email_verified_at,
+  u.verification_sent_at,
+  u.totp_secret,
+  u.totp_enabled_at,
+  u.totp_last_step,
+  u.role,
+  u.display_name,
+  u.bio,
+  u.website,
+  u.social_links
 FROM users u
 WHERE u.email = $1 AND u.is_active = TRUE
 `
@@ -64,6 +241,468 @@ func (q *Queries) GetByEmail(ctx context.Context, email string) (User, error) {
 		&i.TokenInvalidBefore,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.Role,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Website,
+		&i.SocialLinks,
 	)
 	return i, err
 }
+
+const getByID = `-- name: GetByID :one
+SELECT
+  u.id,
+  u.email,
+  u.username,
+  u.password_hash,
+  u.is_active,
+  u.token_invalid_before,
+  u.created_at,
+  u.updated_at,
+  u.email_verified_at,
+  u.verification_sent_at,
+  u.totp_secret,
+  u.totp_enabled_at,
+  u.totp_last_step,
+  u.role,
+  u.display_name,
+  u.bio,
+  u.website,
+  u.social_links
+FROM users u
+WHERE u.id = $1 AND u.is_active = TRUE
+`
+
+func (q *Queries) GetByID(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRow(ctx, getByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.IsActive,
+		&i.TokenInvalidBefore,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.Role,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Website,
+		&i.SocialLinks,
+	)
+	return i, err
+}
+
+const getIDByUsername = `-- name: GetIDByUsername :one
+SELECT u.id FROM users u
+WHERE u.username = $1 AND u.is_active = TRUE
+`
+
+func (q *Queries) GetIDByUsername(ctx context.Context, username string) (int64, error) {
+	row := q.db.QueryRow(ctx, getIDByUsername, username)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getPublicProfile = `-- name: GetPublicProfile :one
+SELECT
+  u.id,
+  u.username,
+  u.display_name,
+  u.bio,
+  u.website,
+  u.social_links,
+  u.created_at,
+  (SELECT count(*) FROM posts p WHERE p.author_id = u.id AND p.status = 'published') AS post_count,
+  (SELECT count(*) FROM follows f JOIN users fu ON fu.id = f.follower_id
+    WHERE f.followee_id = u.id AND fu.is_active = TRUE) AS follower_count,
+  (SELECT count(*) FROM follows f JOIN users fu ON fu.id = f.followee_id
+    WHERE f.follower_id = u.id AND fu.is_active = TRUE) AS following_count
+FROM users u
+WHERE u.username = $1 AND u.is_active = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = u.id AND b.blocked_id = $2
+  )
+`
+
+type GetPublicProfileParams struct {
+	Username string
+	ViewerID int64
+}
+
+type GetPublicProfileRow struct {
+	ID             int64
+	Username       string
+	DisplayName    string
+	Bio            string
+	Website        string
+	SocialLinks    []byte
+	CreatedAt      pgtype.Timestamptz
+	PostCount      int64
+	FollowerCount  int64
+	FollowingCount int64
+}
+
+func (q *Queries) GetPublicProfile(ctx context.Context, arg GetPublicProfileParams) (GetPublicProfileRow, error) {
+	row := q.db.QueryRow(ctx, getPublicProfile, arg.Username, arg.ViewerID)
+	var i GetPublicProfileRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Website,
+		&i.SocialLinks,
+		&i.CreatedAt,
+		&i.PostCount,
+		&i.FollowerCount,
+		&i.FollowingCount,
+	)
+	return i, err
+}
+
+const getTokenInvalidBefore = `-- name: GetTokenInvalidBefore :one
+SELECT u.token_invalid_before
+FROM users u
+WHERE u.id = $1 AND u.is_active = TRUE
+`
+
+func (q *Queries) GetTokenInvalidBefore(ctx context.Context, id int64) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, getTokenInvalidBefore, id)
+	var token_invalid_before pgtype.Timestamptz
+	err := row.Scan(&token_invalid_before)
+	return token_invalid_before, err
+}
+
+const getVisibleIDByUsername = `-- name: GetVisibleIDByUsername :one
+SELECT u.id FROM users u
+WHERE u.username = $1 AND u.is_active = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE b.blocker_id = u.id AND b.blocked_id = $2
+  )
+`
+
+type GetVisibleIDByUsernameParams struct {
+	Username string
+	ViewerID int64
+}
+
+// Resolves an active user the viewer may see, that is one who has not blocked the viewer.
+func (q *Queries) GetVisibleIDByUsername(ctx context.Context, arg GetVisibleIDByUsernameParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getVisibleIDByUsername, arg.Username, arg.ViewerID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const invalidateUserTokens = `-- name: InvalidateUserTokens :execrows
+UPDATE users SET token_invalid_before = $1, updated_at = now()
+WHERE id = $2
+`
+
+type InvalidateUserTokensParams struct {
+	Cutoff pgtype.Timestamptz
+	ID     int64
+}
+
+// Moves the cutoff forward so every token issued before @cutoff is rejected. The caller picks
+// the cutoff from the same clock that stamps token iat claims.
+func (q *Queries) InvalidateUserTokens(ctx context.Context, arg InvalidateUserTokensParams) (int64, error) {
+	result, err := q.db.Exec(ctx, invalidateUserTokens, arg.Cutoff, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT
+  u.id,
+  u.email,
+  u.username,
+  u.password_hash,
+  u.is_active,
+  u.token_invalid_before,
+  u.created_at,
+  u.updated_at,
+  u.email_verified_at,
+  u.verification_sent_at,
+  u.totp_secret,
+  u.totp_enabled_at,
+  u.totp_last_step,
+  u.role,
+  u.display_name,
+  u.bio,
+  u.website,
+  u.social_links
+FROM users u
+ORDER BY u.id
+LIMIT $1 OFFSET $2
+`
+
+type ListUsersParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.PasswordHash,
+			&i.IsActive,
+			&i.TokenInvalidBefore,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmailVerifiedAt,
+			&i.VerificationSentAt,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastStep,
+			&i.Role,
+			&i.DisplayName,
+			&i.Bio,
+			&i.Website,
+			&i.SocialLinks,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markVerificationSent = `-- name: MarkVerificationSent :execrows
+UPDATE users SET verification_sent_at = now()
+WHERE id = $1
+  AND email_verified_at IS NULL
+  AND (verification_sent_at IS NULL OR verification_sent_at < $2)
+`
+
+type MarkVerificationSentParams struct {
+	ID                 int64
+	VerificationSentAt pgtype.Timestamptz
+}
+
+// Claims the resend slot only when the previous email is older than the given cutoff.
+func (q *Queries) MarkVerificationSent(ctx context.Context, arg MarkVerificationSentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markVerificationSent, arg.ID, arg.VerificationSentAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reactivateUser = `-- name: ReactivateUser :execrows
+UPDATE users SET is_active = TRUE, updated_at = now()
+WHERE id = $1 AND is_active = FALSE
+`
+
+func (q *Queries) ReactivateUser(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, reactivateUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rehashPassword = `-- name: RehashPassword :exec
+UPDATE users SET password_hash = $1
+WHERE id = $2 AND password_hash = $3
+`
+
+type RehashPasswordParams struct {
+	NewHash string
+	ID      int64
+	OldHash string
+}
+
+// Swaps in an upgraded hash only if the password was not changed in the meantime.
+func (q *Queries) RehashPassword(ctx context.Context, arg RehashPasswordParams) error {
+	_, err := q.db.Exec(ctx, rehashPassword, arg.NewHash, arg.ID, arg.OldHash)
+	return err
+}
+
+const releaseVerificationSent = `-- name: ReleaseVerificationSent :exec
+UPDATE users SET verification_sent_at = NULL
+WHERE id = $1 AND email_verified_at IS NULL
+`
+
+// Gives back a slot claimed by MarkVerificationSent when the email could not be sent. No one else
+// can claim the slot while it is held, so clearing it never undoes another send.
+func (q *Queries) ReleaseVerificationSent(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, releaseVerificationSent, id)
+	return err
+}
+
+const setTOTPSecret = `-- name: SetTOTPSecret :execrows
+UPDATE users SET totp_secret = $2, updated_at = now()
+WHERE id = $1 AND totp_enabled_at IS NULL
+`
+
+type SetTOTPSecretParams struct {
+	ID         int64
+	TotpSecret pgtype.Text
+}
+
+// Stores a pending secret; it only takes effect once EnableTOTP confirms a code.
+func (q *Queries) SetTOTPSecret(ctx context.Context, arg SetTOTPSecretParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setTOTPSecret, arg.ID, arg.TotpSecret)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setUserRole = `-- name: SetUserRole :execrows
+UPDATE users SET role = $2, updated_at = now()
+WHERE id = $1
+`
+
+type SetUserRoleParams struct {
+	ID   int64
+	Role string
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setUserRole, arg.ID, arg.Role)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateEmail = `-- name: UpdateEmail :execrows
+UPDATE users SET email = $1, email_verified_at = now(), updated_at = now()
+WHERE id = $2 AND email = $3 AND is_active = TRUE
+`
+
+type UpdateEmailParams struct {
+	NewEmail     string
+	ID           int64
+	CurrentEmail string
+}
+
+// Applies a confirmed email change only if the address has not changed since the link was sent.
+func (q *Queries) UpdateEmail(ctx context.Context, arg UpdateEmailParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateEmail, arg.NewEmail, arg.ID, arg.CurrentEmail)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updatePasswordHash = `-- name: UpdatePasswordHash :exec
+UPDATE users SET password_hash = $2, updated_at = now()
+WHERE id = $1
+`
+
+type UpdatePasswordHashParams struct {
+	ID           int64
+	PasswordHash string
+}
+
+func (q *Queries) UpdatePasswordHash(ctx context.Context, arg UpdatePasswordHashParams) error {
+	_, err := q.db.Exec(ctx, updatePasswordHash, arg.ID, arg.PasswordHash)
+	return err
+}
+
+const updateProfile = `-- name: UpdateProfile :execrows
+UPDATE users SET
+  username = COALESCE($1, username),
+  display_name = COALESCE($2, display_name),
+  bio = COALESCE($3, bio),
+  website = COALESCE($4, website),
+  social_links = COALESCE($5, social_links),
+  updated_at = now()
+WHERE id = $6 AND is_active = TRUE
+`
+
+type UpdateProfileParams struct {
+	Username    pgtype.Text
+	DisplayName pgtype.Text
+	Bio         pgtype.Text
+	Website     pgtype.Text
+	SocialLinks []byte
+	ID          int64
+}
+
+// Only the fields passed as non-NULL are changed.
+func (q *Queries) UpdateProfile(ctx context.Context, arg UpdateProfileParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateProfile,
+		arg.Username,
+		arg.DisplayName,
+		arg.Bio,
+		arg.Website,
+		arg.SocialLinks,
+		arg.ID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const useTOTPStep = `-- name: UseTOTPStep :execrows
+UPDATE users SET totp_last_step = $2
+WHERE id = $1 AND totp_last_step < $2
+`
+
+type UseTOTPStepParams struct {
+	ID           int64
+	TotpLastStep int64
+}
+
+// Accepts each TOTP time step at most once so an observed code cannot be replayed.
+func (q *Queries) UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error) {
+	result, err := q.db.Exec(ctx, useTOTPStep, arg.ID, arg.TotpLastStep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const verifyEmail = `-- name: VerifyEmail :execrows
+UPDATE users SET is_active = TRUE, email_verified_at = now(), updated_at = now()
+WHERE id = $1 AND email = $2 AND email_verified_at IS NULL
+`
+
+type VerifyEmailParams struct {
+	ID    int64
+	Email string
+}
+
+func (q *Queries) VerifyEmail(ctx context.Context, arg VerifyEmailParams) (int64, error) {
+	result, err := q.db.Exec(ctx, verifyEmail, arg.ID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
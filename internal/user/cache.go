@@ -0,0 +1,68 @@
+package user
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache is a small concurrency-safe map whose entries expire after a fixed duration.
+// It keeps hot per-request lookups (such as token revocation state) off the database while
+// still bounding how long another replica's change can go unnoticed.
+type ttlCache[K comparable, V any] struct {
+	mu        sync.RWMutex
+	ttl       time.Duration
+	entries   map[K]ttlEntry[V]
+	lastSweep time.Time
+}
+
+type ttlEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// newTTLCache creates an empty cache whose entries live for ttl.
+func newTTLCache[K comparable, V any](ttl time.Duration) *ttlCache[K, V] {
+	return &ttlCache[K, V]{
+		ttl:     ttl,
+		entries: make(map[K]ttlEntry[V]),
+	}
+}
+
+// Get returns the cached value for key if it exists and has not expired.
+func (c *ttlCache[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// Set stores value for key, replacing any previous entry.
+func (c *ttlCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Drop expired entries at most once per ttl so the map cannot grow without bound.
+	now := time.Now()
+	if now.Sub(c.lastSweep) > c.ttl {
+		for k, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	c.entries[key] = ttlEntry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// Delete removes key from the cache.
+func (c *ttlCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
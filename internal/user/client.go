@@ -0,0 +1,51 @@
+package user
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+var clientInfoKey ctxKey = "client_info"
+
+// ClientInfo describes where a request came from. It is attached to auth events and sessions.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// ClientInfoMiddleware captures the caller's IP, user agent, and request ID into the context.
+// It must run after chi's RequestID and RealIP middleware so both values are already resolved.
+func ClientInfoMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// RealIP replaces RemoteAddr with a bare IP; without a proxy header it still carries a port.
+		ip := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+
+		info := ClientInfo{
+			IP:        ip,
+			UserAgent: r.UserAgent(),
+			RequestID: middleware.GetReqID(r.Context()),
+		}
+
+		ctx := context.WithValue(r.Context(), clientInfoKey, info)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ClientInfoFromContext returns the request's client details, or a zero value outside HTTP requests.
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey).(ClientInfo)
+	return info
+}
+
+// clientHash fingerprints the caller by IP and user agent so a token can be bound to the client
+// that asked for it without storing either value next to the token.
+func clientHash(client ClientInfo) string {
+	return hashToken(client.IP + "\x00" + client.UserAgent)
+}
@@ -11,6 +11,226 @@ type User struct {
 	PasswordHash       string
 	IsActive           bool
 	TokenInvalidBefore time.Time
+	EmailVerifiedAt    time.Time
+	VerificationSentAt time.Time
+	TOTPSecret         string
+	TOTPEnabledAt      time.Time
+	TOTPLastStep       int64
+	Role               Role
+	DisplayName        string
+	Bio                string
+	Website            string
+	SocialLinks        map[string]string
 	CreatedAt          time.Time
 	UpdatedAt          time.Time
 }
+
+// PublicProfile is the part of an account that anyone may see on an author page.
+type PublicProfile struct {
+	Username       string
+	DisplayName    string
+	Bio            string
+	Website        string
+	SocialLinks    map[string]string
+	PostCount      int64
+	FollowerCount  int64
+	FollowingCount int64
+	JoinedAt       time.Time
+}
+
+// FollowEntry is one account in a follower or following list.
+type FollowEntry struct {
+	Username    string
+	DisplayName string
+	FollowedAt  time.Time
+}
+
+// RelationEntry is one account in a block or mute list.
+type RelationEntry struct {
+	Username    string
+	DisplayName string
+	CreatedAt   time.Time
+}
+
+// RefreshToken is an opaque, single-use credential that can be exchanged for a new access token.
+// Tokens issued from the same sign-in share a FamilyID so a reused token can revoke the whole chain.
+type RefreshToken struct {
+	ID        int64
+	UserID    int64
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	RotatedAt time.Time
+	RevokedAt time.Time
+	CreatedAt time.Time
+}
+
+// Session is one signed-in device. Its ID doubles as the refresh token family ID and is
+// carried in the sid claim of every access token issued for it.
+type Session struct {
+	ID         string
+	UserID     int64
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	RevokedAt  time.Time
+}
+
+// MagicLinkToken is a single-use, emailed sign-in link. It only works from the client that
+// asked for it, identified by ClientHash.
+type MagicLinkToken struct {
+	ID         int64
+	UserID     int64
+	TokenHash  string
+	ClientHash string
+	ExpiresAt  time.Time
+	UsedAt     time.Time
+	CreatedAt  time.Time
+}
+
+// PersonalAccessToken is a long-lived, scoped credential for automation such as CI jobs.
+// Only a hash of the token is stored; Prefix is kept in clear so users can recognise it.
+type PersonalAccessToken struct {
+	ID         int64
+	UserID     int64
+	Name       string
+	Prefix     string
+	Scopes     []string
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	RevokedAt  time.Time
+	CreatedAt  time.Time
+}
+
+// Invite lets someone register while registration is invite-only. Only a hash of the code is
+// stored; Prefix is kept in clear so its creator can recognise it. An empty Email means any address
+// may use it.
+type Invite struct {
+	ID        int64
+	CreatedBy int64
+	Prefix    string
+	Email     string
+	MaxUses   int32
+	UseCount  int32
+	ExpiresAt time.Time
+	RevokedAt time.Time
+	CreatedAt time.Time
+}
+
+// RegistrationMode controls who may create an account through signup.
+type RegistrationMode string
+
+// Registration modes. Open is the default.
+const (
+	// RegistrationOpen lets anyone sign up. Invite codes are still honoured and counted.
+	RegistrationOpen RegistrationMode = "open"
+	// RegistrationInviteOnly requires a valid invite code to sign up.
+	RegistrationInviteOnly RegistrationMode = "invite-only"
+	// RegistrationClosed refuses every signup, with or without an invite.
+	RegistrationClosed RegistrationMode = "closed"
+)
+
+// Valid reports whether m is one of the known registration modes.
+func (m RegistrationMode) Valid() bool {
+	switch m {
+	case RegistrationOpen, RegistrationInviteOnly, RegistrationClosed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Scopes that can be granted to personal access tokens.
+const (
+	ScopePostsRead  = "posts:read"
+	ScopePostsWrite = "posts:write"
+)
+
+// ScopeAccount guards account management routes. It is implied by interactive sessions and is
+// never granted to personal access tokens, so a leaked token cannot change the account itself.
+const ScopeAccount = "account"
+
+// AuthThrottle tracks consecutive failed authentication attempts for one account or IP key.
+type AuthThrottle struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// AuthEvent is an entry in the authentication audit log.
+type AuthEvent struct {
+	ID        int64
+	UserID    int64
+	Type      string
+	IP        string
+	UserAgent string
+	RequestID string
+	Metadata  map[string]any
+	CreatedAt time.Time
+}
+
+// AuthEventFilter narrows the admin audit log search. Zero fields do not filter.
+type AuthEventFilter struct {
+	UserID    int64
+	Type      string
+	IP        string
+	RequestID string
+	Since     time.Time
+	Until     time.Time
+	Limit     int32
+	Offset    int32
+}
+
+// AuthoredPost is a post as it appears in the author's personal data export. PublishedAt is zero
+// for posts that are not published.
+type AuthoredPost struct {
+	ID          int64
+	Title       string
+	Slug        string
+	Content     string
+	Status      string
+	PublishedAt time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// AccountExport is everything devlog stores about one account, gathered for a data export.
+type AccountExport struct {
+	User       User
+	Posts      []AuthoredPost
+	Events     []AuthEvent
+	Sessions   []Session
+	Following  []FollowEntry
+	Followers  []FollowEntry
+	ExportedAt time.Time
+}
+
+// Auth event types recorded in the audit log.
+const (
+	EventSignUp        = "signup"
+	EventSignInSuccess = "signin_success"
+	// EventSignInFailure carries a "reason" in its metadata and has no user when the email is unknown.
+	EventSignInFailure = "signin_failure"
+	EventTokenRefresh  = "token_refresh"
+	// EventRefreshTokenReuse is recorded when a rotated refresh token is presented again and its
+	// whole family is revoked.
+	EventRefreshTokenReuse = "refresh_token_reuse"
+	EventPasswordChanged   = "password_changed"
+	EventPasswordReset     = "password_reset"
+	EventTwoFactorEnabled  = "two_factor_enabled"
+	EventTwoFactorDisabled = "two_factor_disabled"
+	EventLockout           = "lockout"
+	EventAccountUnlocked   = "account_unlocked"
+	// EventMagicLinkReplay is recorded when a used sign-in link is presented again.
+	EventMagicLinkReplay = "magic_link_replay"
+	// EventMagicLinkClientMismatch is recorded when a sign-in link is opened from another client.
+	EventMagicLinkClientMismatch = "magic_link_client_mismatch"
+	// EventInviteRedeemed is recorded for an account created with an invite code.
+	EventInviteRedeemed = "invite_redeemed"
+	// EventDeletionRequested is recorded when a user schedules their account for deletion.
+	EventDeletionRequested = "deletion_requested"
+	// EventDeletionCancelled is recorded when a scheduled deletion is called off.
+	EventDeletionCancelled = "deletion_cancelled"
+)
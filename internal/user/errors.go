@@ -1,6 +1,9 @@
 package user
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // Domain-level user/auth errors shared across repository, service, and handler layers.
 var (
@@ -9,8 +12,60 @@ var (
 	ErrEmailTaken         = errors.New("email already taken")
 	ErrUsernameTaken      = errors.New("username already taken")
 	ErrConflict           = errors.New("conflict")
-	ErrJWTSecretNotSet    = errors.New("jwt secret not set")
 	ErrInvalidToken       = errors.New("invalid token")
 	ErrUnknownClaimsType  = errors.New("unknown claims type")
 	ErrWeakPassword       = errors.New("password is weak")
+	ErrBreachedPassword   = errors.New("password has appeared in a data breach")
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	ErrTokenRevoked       = errors.New("token revoked")
+	ErrEmailNotVerified   = errors.New("email not verified")
+	ErrTwoFactorEnabled   = errors.New("two-factor authentication already enabled")
+	ErrTwoFactorDisabled  = errors.New("two-factor authentication not enabled")
+	ErrInvalidTwoFactor   = errors.New("invalid two-factor code")
+	ErrTokenNotFound      = errors.New("token not found")
+	ErrInvalidExpiry      = errors.New("expiry must be in the future")
+	ErrInsufficientScope  = errors.New("insufficient scope")
+	ErrForbidden          = errors.New("forbidden")
+	ErrCannotModifySelf   = errors.New("cannot perform this action on your own account")
+	ErrInvalidRole        = errors.New("invalid role")
+	ErrTooManyAttempts    = errors.New("too many failed attempts, slow down")
+	ErrAccountLocked      = errors.New("account temporarily locked")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrRegistrationClosed = errors.New("registration is closed")
+	ErrInviteRequired     = errors.New("an invite code is required to sign up")
+	ErrInvalidInvite      = errors.New("invalid or expired invite code")
+	ErrInviteNotFound     = errors.New("invite not found")
+	ErrCannotFollowSelf   = errors.New("cannot follow yourself")
+	ErrCannotBlockSelf    = errors.New("cannot block yourself")
+	ErrCannotMuteSelf     = errors.New("cannot mute yourself")
+	ErrUserBlocked        = errors.New("interaction blocked between these users")
 )
+
+// RetryAfterError wraps a throttling error with how long the client should wait before retrying.
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// PasswordRejectedError explains why a new password was refused. It wraps ErrWeakPassword or
+// ErrBreachedPassword and carries a message meant for the person choosing the password.
+type PasswordRejectedError struct {
+	Err    error
+	Reason string
+}
+
+func (e *PasswordRejectedError) Error() string {
+	return e.Reason
+}
+
+func (e *PasswordRejectedError) Unwrap() error {
+	return e.Err
+}
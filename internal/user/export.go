@@ -0,0 +1,236 @@
+package user
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// exportProfile is the profile.json document of a data export. Secrets such as the password
+// hash and the TOTP seed are left out; they are not personal data the user could use elsewhere.
+type exportProfile struct {
+	ID               int64             `json:"id"`
+	Email            string            `json:"email"`
+	Username         string            `json:"username"`
+	DisplayName      string            `json:"display_name"`
+	Bio              string            `json:"bio"`
+	Website          string            `json:"website"`
+	SocialLinks      map[string]string `json:"social_links"`
+	Role             Role              `json:"role"`
+	EmailVerifiedAt  *time.Time        `json:"email_verified_at"`
+	TwoFactorEnabled bool              `json:"two_factor_enabled"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+type exportPost struct {
+	ID          int64      `json:"id"`
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug"`
+	Content     string     `json:"content"`
+	Status      string     `json:"status"`
+	PublishedAt *time.Time `json:"published_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type exportEvent struct {
+	Type      string         `json:"type"`
+	IP        string         `json:"ip"`
+	UserAgent string         `json:"user_agent"`
+	Metadata  map[string]any `json:"metadata"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type exportSession struct {
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+type exportFollow struct {
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// exportReadme explains the layout of the archive to whoever opens it.
+const exportReadme = `# devlog data export
+
+This archive contains everything devlog stores about your account.
+
+- profile.json, profile.md: your account and public profile
+- posts.json: all of your posts
+- posts/<id>.md: each post as Markdown
+- activity/security_events.json: sign-ins, lockouts and other security events
+- activity/sessions.json: devices that are signed in to your account
+- activity/following.json, activity/followers.json: your follows
+`
+
+// writeAccountExport streams export as a ZIP archive of JSON and Markdown files to w.
+func writeAccountExport(w io.Writer, export AccountExport) error {
+	zw := zip.NewWriter(w)
+
+	user := export.User
+	profile := exportProfile{
+		ID:               user.ID,
+		Email:            user.Email,
+		Username:         user.Username,
+		DisplayName:      user.DisplayName,
+		Bio:              user.Bio,
+		Website:          user.Website,
+		SocialLinks:      user.SocialLinks,
+		Role:             user.Role,
+		TwoFactorEnabled: !user.TOTPEnabledAt.IsZero(),
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+	}
+	if !user.EmailVerifiedAt.IsZero() {
+		profile.EmailVerifiedAt = &user.EmailVerifiedAt
+	}
+
+	posts := make([]exportPost, 0, len(export.Posts))
+	for _, p := range export.Posts {
+		post := exportPost{
+			ID:        p.ID,
+			Title:     p.Title,
+			Slug:      p.Slug,
+			Content:   p.Content,
+			Status:    p.Status,
+			CreatedAt: p.CreatedAt,
+			UpdatedAt: p.UpdatedAt,
+		}
+		if !p.PublishedAt.IsZero() {
+			post.PublishedAt = &p.PublishedAt
+		}
+		posts = append(posts, post)
+	}
+
+	events := make([]exportEvent, 0, len(export.Events))
+	for _, e := range export.Events {
+		events = append(events, exportEvent{
+			Type:      e.Type,
+			IP:        e.IP,
+			UserAgent: e.UserAgent,
+			Metadata:  e.Metadata,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	sessions := make([]exportSession, 0, len(export.Sessions))
+	for _, s := range export.Sessions {
+		sessions = append(sessions, exportSession{
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+		})
+	}
+
+	files := []struct {
+		name string
+		body any
+	}{
+		{"profile.json", profile},
+		{"posts.json", posts},
+		{"activity/security_events.json", events},
+		{"activity/sessions.json", sessions},
+		{"activity/following.json", exportFollows(export.Following)},
+		{"activity/followers.json", exportFollows(export.Followers)},
+	}
+
+	if err := writeZipText(zw, "README.md", export.ExportedAt, exportReadme); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := writeZipJSON(zw, f.name, export.ExportedAt, f.body); err != nil {
+			return err
+		}
+	}
+	if err := writeZipText(zw, "profile.md", export.ExportedAt, profileMarkdown(user)); err != nil {
+		return err
+	}
+	for _, p := range export.Posts {
+		name := fmt.Sprintf("posts/%d.md", p.ID)
+		if err := writeZipText(zw, name, p.UpdatedAt, postMarkdown(p)); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func exportFollows(entries []FollowEntry) []exportFollow {
+	follows := make([]exportFollow, 0, len(entries))
+	for _, e := range entries {
+		follows = append(follows, exportFollow{Username: e.Username, FollowedAt: e.FollowedAt})
+	}
+	return follows
+}
+
+// writeZipJSON adds an indented JSON file to the archive.
+func writeZipJSON(zw *zip.Writer, name string, modified time.Time, v any) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return fmt.Errorf("export %s: %w", name, err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("export %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeZipText adds a text file to the archive.
+func writeZipText(zw *zip.Writer, name string, modified time.Time, text string) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return fmt.Errorf("export %s: %w", name, err)
+	}
+	if _, err := io.WriteString(f, text); err != nil {
+		return fmt.Errorf("export %s: %w", name, err)
+	}
+	return nil
+}
+
+// profileMarkdown renders the account as a human-readable Markdown page.
+func profileMarkdown(user User) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", user.Username)
+	fmt.Fprintf(&b, "- Email: %s\n", user.Email)
+	if user.DisplayName != "" {
+		fmt.Fprintf(&b, "- Display name: %s\n", user.DisplayName)
+	}
+	if user.Website != "" {
+		fmt.Fprintf(&b, "- Website: %s\n", user.Website)
+	}
+	for platform, link := range user.SocialLinks {
+		fmt.Fprintf(&b, "- %s: %s\n", platform, link)
+	}
+	fmt.Fprintf(&b, "- Role: %s\n", user.Role)
+	fmt.Fprintf(&b, "- Joined: %s\n", user.CreatedAt.UTC().Format(time.RFC3339))
+	if user.Bio != "" {
+		fmt.Fprintf(&b, "\n%s\n", user.Bio)
+	}
+	return b.String()
+}
+
+// postMarkdown renders a post as Markdown with its status and dates under the title.
+func postMarkdown(p AuthoredPost) string {
+	state := "Draft"
+	switch {
+	case p.Status == "archived":
+		state = "Archived"
+	case !p.PublishedAt.IsZero():
+		state = "Published " + p.PublishedAt.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("# %s\n\n_%s, last updated %s_\n\n%s\n",
+		p.Title,
+		state,
+		p.UpdatedAt.UTC().Format(time.RFC3339),
+		p.Content)
+}
@@ -1,9 +1,14 @@
 package user
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/OnatArslan/devlog/internal/httpx"
@@ -29,9 +34,10 @@ func NewUserHandler(svc *Service, validate *validator.Validate) *Handler {
 // SignUpRequest is the expected JSON payload for user registration.
 type SignUpRequest struct {
 	Email           string `json:"email" validate:"required,email"`
-	Username        string `json:"username" validate:"required,alphanum"`
+	Username        string `json:"username" validate:"required,username"`
 	Password        string `json:"password" validate:"required,strong-password"`
 	PasswordConfirm string `json:"passwordConfirm" validate:"required,eqfield=Password"`
+	InviteCode      string `json:"inviteCode" validate:"omitempty,max=100"`
 }
 
 // SignUpResponse is the public response body returned after successful registration.
@@ -63,12 +69,23 @@ func (h *Handler) SignUp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call the service layer to create a new user account.
-	user, err := h.svc.SignUp(r.Context(), SignUpInput{Email: req.Email, Username: req.Username, Password: req.Password})
+	user, err := h.svc.SignUp(r.Context(), SignUpInput{
+		Email:      req.Email,
+		Username:   req.Username,
+		Password:   req.Password,
+		InviteCode: req.InviteCode,
+	})
 	if err != nil {
 		// Map domain conflicts and unexpected failures to HTTP status codes.
 		switch {
+		case errors.Is(err, ErrRegistrationClosed), errors.Is(err, ErrInviteRequired):
+			httpx.WriteError(w, http.StatusForbidden, err)
+		case errors.Is(err, ErrInvalidInvite):
+			httpx.WriteError(w, http.StatusBadRequest, err)
 		case errors.Is(err, ErrEmailTaken), errors.Is(err, ErrUsernameTaken), errors.Is(err, ErrConflict):
 			httpx.WriteError(w, http.StatusConflict, err)
+		case errors.Is(err, ErrWeakPassword), errors.Is(err, ErrBreachedPassword):
+			writePasswordRejected(w, err)
 		default:
 			httpx.WriteError(w, http.StatusInternalServerError, err)
 		}
@@ -91,12 +108,14 @@ type SignInRequest struct {
 	Password string `json:"password" validate:"required,min=8,max=64"`
 }
 
-// SignInResponse returns user-safe identity fields with access token details.
+// SignInResponse returns user-safe identity fields with access and refresh token details.
 type SignInResponse struct {
-	User        SignInUserResult `json:"user"`
-	AccessToken string           `json:"access_token"`
-	TokenType   string           `json:"token_type"` // "Bearer"
-	ExpiresAt   time.Time        `json:"expires_at"`
+	User                  SignInUserResult `json:"user"`
+	AccessToken           string           `json:"access_token"`
+	TokenType             string           `json:"token_type"` // "Bearer"
+	ExpiresAt             time.Time        `json:"expires_at"`
+	RefreshToken          string           `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time        `json:"refresh_token_expires_at"`
 }
 
 // SignInUserResult contains only non-sensitive user fields for login responses.
@@ -136,41 +155,206 @@ func (h *Handler) SignIn(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case errors.Is(err, ErrInvalidCredentials):
 			httpx.WriteError(w, http.StatusUnauthorized, err)
-		case errors.Is(err, ErrJWTSecretNotSet):
+		case errors.Is(err, ErrEmailNotVerified):
+			httpx.WriteError(w, http.StatusForbidden, err)
+		case errors.Is(err, ErrTooManyAttempts), errors.Is(err, ErrAccountLocked):
+			writeRetryAfter(w, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	writeSignInResult(w, signInOutput)
+}
+
+// writeSignInResult answers a first-factor signin with tokens, or with a challenge for accounts
+// with 2FA, which must call /signin/2fa next.
+func writeSignInResult(w http.ResponseWriter, out SignInOutput) {
+	if out.ChallengeToken != "" {
+		httpx.WriteJSON(w, http.StatusOK, TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    out.ChallengeToken,
+			ExpiresAt:         out.ChallengeExpiresAt,
+		})
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, newSignInResponse(out))
+}
+
+// MagicLinkRequest is the expected JSON payload for requesting a sign-in link by email.
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// RequestMagicLink emails a sign-in link when the account exists and always answers the same way.
+func (h *Handler) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req MagicLinkRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.RequestMagicLink(r.Context(), req.Email); err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusAccepted, map[string]any{
+		"status": "if an account exists for this email, a sign-in link has been sent",
+	})
+}
+
+// MagicLinkCallback exchanges the token from an emailed sign-in link for the signin payload.
+func (h *Handler) MagicLinkCallback(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		httpx.WriteError(w, http.StatusBadRequest, ErrInvalidToken)
+		return
+	}
+
+	out, err := h.svc.SignInMagicLink(r.Context(), token)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidToken):
+			httpx.WriteError(w, http.StatusUnauthorized, err)
+		case errors.Is(err, ErrTooManyAttempts), errors.Is(err, ErrAccountLocked):
+			writeRetryAfter(w, err)
+		default:
 			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	writeSignInResult(w, out)
+}
+
+// TwoFactorChallengeResponse is returned by signin when a second factor is still required.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// SignInTwoFactorRequest is the expected JSON payload for completing a two-factor signin.
+type SignInTwoFactorRequest struct {
+	ChallengeToken string `json:"challengeToken" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+// SignInTwoFactor exchanges a signin challenge and a TOTP or recovery code for tokens.
+func (h *Handler) SignInTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req SignInTwoFactorRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	out, err := h.svc.SignInTwoFactor(r.Context(), req.ChallengeToken, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrInvalidTwoFactor), errors.Is(err, ErrTwoFactorDisabled):
+			httpx.WriteError(w, http.StatusUnauthorized, err)
+		case errors.Is(err, ErrTooManyAttempts), errors.Is(err, ErrAccountLocked):
+			writeRetryAfter(w, err)
 		default:
 			httpx.WriteError(w, http.StatusInternalServerError, err)
 		}
 		return
 	}
 
+	httpx.WriteJSON(w, http.StatusOK, newSignInResponse(out))
+}
+
+// newSignInResponse builds the public token payload shared by signin and token refresh.
+func newSignInResponse(out SignInOutput) SignInResponse {
 	// Build a safe user payload without sensitive fields.
 	userResponse := SignInUserResult{
-		ID:       signInOutput.User.ID,
-		Email:    signInOutput.User.Email,
-		Username: signInOutput.User.Username,
+		ID:       out.User.ID,
+		Email:    out.User.Email,
+		Username: out.User.Username,
 	}
 
 	// Build the final signin response containing token metadata.
-	signInResponse := SignInResponse{
-		User:        userResponse,
-		AccessToken: signInOutput.Token,
-		TokenType:   "Bearer",
-		ExpiresAt:   signInOutput.ExpiresAt,
+	return SignInResponse{
+		User:                  userResponse,
+		AccessToken:           out.Token,
+		TokenType:             "Bearer",
+		ExpiresAt:             out.ExpiresAt,
+		RefreshToken:          out.RefreshToken,
+		RefreshTokenExpiresAt: out.RefreshTokenExpiresAt,
+	}
+}
+
+// RefreshTokenRequest is the expected JSON payload for exchanging a refresh token.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// RefreshToken rotates a refresh token and returns a new access and refresh token pair.
+func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req RefreshTokenRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	out, err := h.svc.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrRefreshTokenReused):
+			httpx.WriteError(w, http.StatusUnauthorized, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
 	}
 
-	httpx.WriteJSON(w, http.StatusOK, signInResponse)
+	httpx.WriteJSON(w, http.StatusOK, newSignInResponse(out))
 }
 
 // GetMeResponse is the JSON response body for the authenticated user's profile.
 type GetMeResponse struct {
-	ID                 int64     `json:"id"`
-	Email              string    `json:"email"`
-	Username           string    `json:"username"`
-	IsActive           bool      `json:"is_active"`
-	TokenInvalidBefore time.Time `json:"token_invalid_before"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	ID                 int64             `json:"id"`
+	Email              string            `json:"email"`
+	Username           string            `json:"username"`
+	IsActive           bool              `json:"is_active"`
+	TokenInvalidBefore time.Time         `json:"token_invalid_before"`
+	TwoFactorEnabled   bool              `json:"two_factor_enabled"`
+	DisplayName        string            `json:"display_name"`
+	Bio                string            `json:"bio"`
+	Website            string            `json:"website"`
+	SocialLinks        map[string]string `json:"social_links"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
 }
 
 // GetMe returns the profile of the currently authenticated user.
@@ -194,27 +378,1597 @@ func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := GetMeResponse{
+	httpx.WriteJSON(w, http.StatusOK, newGetMeResponse(user))
+}
+
+// newGetMeResponse builds the profile payload shared by reading and updating the current user.
+func newGetMeResponse(user User) GetMeResponse {
+	return GetMeResponse{
 		ID:                 user.ID,
 		Email:              user.Email,
 		Username:           user.Username,
 		IsActive:           user.IsActive,
 		TokenInvalidBefore: user.TokenInvalidBefore,
+		TwoFactorEnabled:   !user.TOTPEnabledAt.IsZero(),
+		DisplayName:        user.DisplayName,
+		Bio:                user.Bio,
+		Website:            user.Website,
+		SocialLinks:        user.SocialLinks,
 		CreatedAt:          user.CreatedAt,
 		UpdatedAt:          user.UpdatedAt,
 	}
+}
 
-	httpx.WriteJSON(w, http.StatusOK, response)
+// UpdateMeRequest is the expected JSON payload for editing the current user's profile.
+// Omitted fields are left unchanged.
+type UpdateMeRequest struct {
+	Username    *string           `json:"username" validate:"omitnil,username"`
+	DisplayName *string           `json:"displayName" validate:"omitnil,display-name"`
+	Bio         *string           `json:"bio" validate:"omitnil,max=500,plain-text"`
+	Website     *string           `json:"website" validate:"omitnil,eq=|web-url"`
+	SocialLinks map[string]string `json:"socialLinks" validate:"omitempty,max=7,dive,keys,social-platform,endkeys,web-url"`
 }
 
-// Routes registers user HTTP routes under the provided chi router.
-func (h *Handler) Routes(r chi.Router) chi.Router {
-	// Register user auth endpoints on the provided router.
-	r.Post("/signup", h.SignUp)
-	r.Post("/signin", h.SignIn)
-	r.Group(func(r chi.Router) {
-		r.Use(h.AuthMiddleware)
-		r.Get("/me", h.GetMe)
+// UpdateMe applies profile changes for the authenticated user.
+func (h *Handler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	var req UpdateMeRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.svc.UpdateProfile(r.Context(), ctxUser.ID, UpdateProfileInput(req))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUsernameTaken), errors.Is(err, ErrConflict):
+			httpx.WriteError(w, http.StatusConflict, err)
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, newGetMeResponse(user))
+}
+
+// PublicProfileResponse is the JSON response body for an author's public profile.
+type PublicProfileResponse struct {
+	Username       string            `json:"username"`
+	DisplayName    string            `json:"display_name"`
+	Bio            string            `json:"bio"`
+	Website        string            `json:"website"`
+	SocialLinks    map[string]string `json:"social_links"`
+	PostCount      int64             `json:"post_count"`
+	FollowerCount  int64             `json:"follower_count"`
+	FollowingCount int64             `json:"following_count"`
+	JoinedAt       time.Time         `json:"joined_at"`
+}
+
+// GetPublicProfile returns the public profile of the author named in the URL.
+func (h *Handler) GetPublicProfile(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.svc.GetPublicProfile(r.Context(), viewerID(r), chi.URLParam(r, "username"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, PublicProfileResponse(profile))
+}
+
+// FollowUser makes the authenticated user follow the author named in the URL.
+func (h *Handler) FollowUser(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	if err := h.svc.FollowUser(r.Context(), ctxUser.ID, chi.URLParam(r, "username")); err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		case errors.Is(err, ErrCannotFollowSelf):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		case errors.Is(err, ErrUserBlocked):
+			httpx.WriteError(w, http.StatusForbidden, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnfollowUser makes the authenticated user stop following the author named in the URL.
+func (h *Handler) UnfollowUser(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	if err := h.svc.UnfollowUser(r.Context(), ctxUser.ID, chi.URLParam(r, "username")); err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FollowEntryResponse is one account in a follower or following list.
+type FollowEntryResponse struct {
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	FollowedAt  time.Time `json:"followed_at"`
+}
+
+// FollowListResponse is the JSON response body for follower and following lists. Total counts
+// every entry, while Count only counts the ones on this page.
+type FollowListResponse struct {
+	Total  int64                 `json:"total"`
+	Count  int                   `json:"count"`
+	Limit  int32                 `json:"limit"`
+	Offset int32                 `json:"offset"`
+	Users  []FollowEntryResponse `json:"users"`
+}
+
+// ListFollowers handles paginated requests to list who follows the author named in the URL.
+func (h *Handler) ListFollowers(w http.ResponseWriter, r *http.Request) {
+	h.writeFollowList(w, r, h.svc.ListFollowers)
+}
+
+// ListFollowing handles paginated requests to list who the author named in the URL follows.
+func (h *Handler) ListFollowing(w http.ResponseWriter, r *http.Request) {
+	h.writeFollowList(w, r, h.svc.ListFollowing)
+}
+
+// writeFollowList answers a follower or following list request using list to load the page.
+func (h *Handler) writeFollowList(w http.ResponseWriter, r *http.Request,
+	list func(ctx context.Context, viewerID int64, username string, input ListUsersInput) ([]FollowEntry, int64, error)) {
+	input, err := parseListUsersInput(r)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	entries, total, err := list(r.Context(), viewerID(r), chi.URLParam(r, "username"), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	resp := make([]FollowEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		resp = append(resp, FollowEntryResponse(entry))
+	}
+
+	normalized := NormalizeListUsersInput(input)
+	httpx.WriteJSON(w, http.StatusOK, FollowListResponse{
+		Total:  total,
+		Count:  len(resp),
+		Limit:  normalized.Limit,
+		Offset: normalized.Offset,
+		Users:  resp,
+	})
+}
+
+// BlockUser makes the authenticated user block the account named in the URL.
+func (h *Handler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	if err := h.svc.BlockUser(r.Context(), ctxUser.ID, chi.URLParam(r, "username")); err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		case errors.Is(err, ErrCannotBlockSelf):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnblockUser lifts the authenticated user's block on the account named in the URL.
+func (h *Handler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	if err := h.svc.UnblockUser(r.Context(), ctxUser.ID, chi.URLParam(r, "username")); err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MuteUser makes the authenticated user mute the account named in the URL.
+func (h *Handler) MuteUser(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	if err := h.svc.MuteUser(r.Context(), ctxUser.ID, chi.URLParam(r, "username")); err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		case errors.Is(err, ErrCannotMuteSelf):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnmuteUser lifts the authenticated user's mute on the account named in the URL.
+func (h *Handler) UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	if err := h.svc.UnmuteUser(r.Context(), ctxUser.ID, chi.URLParam(r, "username")); err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RelationEntryResponse is one account in a block or mute list.
+type RelationEntryResponse struct {
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ListBlockedUsers handles paginated requests for the accounts the authenticated user has blocked.
+func (h *Handler) ListBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	h.writeRelationList(w, r, h.svc.ListBlockedUsers)
+}
+
+// ListMutedUsers handles paginated requests for the accounts the authenticated user has muted.
+func (h *Handler) ListMutedUsers(w http.ResponseWriter, r *http.Request) {
+	h.writeRelationList(w, r, h.svc.ListMutedUsers)
+}
+
+// writeRelationList answers a block or mute list request using list to load the page.
+func (h *Handler) writeRelationList(w http.ResponseWriter, r *http.Request,
+	list func(ctx context.Context, userID int64, input ListUsersInput) ([]RelationEntry, error)) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	input, err := parseListUsersInput(r)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	entries, err := list(r.Context(), ctxUser.ID, input)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := make([]RelationEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		resp = append(resp, RelationEntryResponse(entry))
+	}
+
+	normalized := NormalizeListUsersInput(input)
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"count":  len(resp),
+		"limit":  normalized.Limit,
+		"offset": normalized.Offset,
+		"users":  resp,
+	})
+}
+
+// ChangePasswordRequest is the expected JSON payload for changing the current user's password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	Password        string `json:"password" validate:"required,strong-password,nefield=CurrentPassword"`
+	PasswordConfirm string `json:"passwordConfirm" validate:"required,eqfield=Password"`
+}
+
+// ChangePassword replaces the authenticated user's password and returns tokens for a new session,
+// since every previously issued token stops working.
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	var req ChangePasswordRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	out, err := h.svc.ChangePassword(r.Context(), ctxUser.ID, req.CurrentPassword, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCredentials):
+			httpx.WriteError(w, http.StatusUnauthorized, err)
+		case errors.Is(err, ErrWeakPassword), errors.Is(err, ErrBreachedPassword):
+			writePasswordRejected(w, err)
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, newSignInResponse(out))
+}
+
+// ChangeEmailRequest is the expected JSON payload for starting an email change.
+type ChangeEmailRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// ChangeEmail emails a confirmation link to the requested new address.
+func (h *Handler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	var req ChangeEmailRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.RequestEmailChange(r.Context(), ctxUser.ID, req.Password, req.Email); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCredentials):
+			httpx.WriteError(w, http.StatusUnauthorized, err)
+		case errors.Is(err, ErrEmailTaken):
+			httpx.WriteError(w, http.StatusConflict, err)
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusAccepted, map[string]any{
+		"status": "confirmation email sent",
+	})
+}
+
+// ConfirmEmailChange applies the email change named by the token in the confirmation link.
+func (h *Handler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		httpx.WriteError(w, http.StatusBadRequest, ErrInvalidToken)
+		return
+	}
+
+	if err := h.svc.ConfirmEmailChange(r.Context(), token); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidToken):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		case errors.Is(err, ErrEmailTaken):
+			httpx.WriteError(w, http.StatusConflict, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"status": "email changed",
+	})
+}
+
+// ExportAccount streams a ZIP archive of everything stored about the authenticated user.
+func (h *Handler) ExportAccount(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	export, err := h.svc.ExportAccount(r.Context(), ctxUser.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	filename := fmt.Sprintf("devlog-export-%s-%s.zip", export.User.Username, export.ExportedAt.UTC().Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	// The status line is already sent, so a failure halfway can only be logged; the client
+	// notices the truncated archive.
+	if err := writeAccountExport(w, export); err != nil {
+		log.Printf("export account %d: %v", ctxUser.ID, err)
+	}
+}
+
+// DeleteAccountRequest is the expected JSON payload for deleting the current user's account.
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// DeleteAccount deactivates the authenticated user's account and schedules it for deletion.
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	var req DeleteAccountRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	purgeAfter, err := h.svc.DeleteAccount(r.Context(), ctxUser.ID, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCredentials):
+			httpx.WriteError(w, http.StatusUnauthorized, err)
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusAccepted, map[string]any{
+		"status":      "account deactivated and scheduled for deletion",
+		"purge_after": purgeAfter,
+	})
+}
+
+// CancelAccountDeletion restores a deactivated account from the link emailed when it was deleted.
+func (h *Handler) CancelAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		httpx.WriteError(w, http.StatusBadRequest, ErrInvalidToken)
+		return
+	}
+
+	if err := h.svc.CancelAccountDeletion(r.Context(), token); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidToken):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"status": "account restored, you can sign in again",
+	})
+}
+
+// LogoutAll invalidates every access and refresh token issued to the authenticated user.
+func (h *Handler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	if err := h.svc.LogoutAll(r.Context(), ctxUser.ID); err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyEmail activates an account from the signed link emailed at signup.
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		httpx.WriteError(w, http.StatusBadRequest, ErrInvalidToken)
+		return
+	}
+
+	if err := h.svc.VerifyEmail(r.Context(), token); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidToken):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"status": "email verified",
+	})
+}
+
+// ResendVerificationRequest is the expected JSON payload for requesting a new verification email.
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResendVerification emails a fresh verification link, at most once per minute per account.
+func (h *Handler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req ResendVerificationRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.ResendVerification(r.Context(), req.Email); err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusAccepted, map[string]any{
+		"status": "if this email belongs to an unverified account, a new link has been sent",
+	})
+}
+
+// writeRetryAfter answers 429 and sets Retry-After when err carries a wait duration.
+func writeRetryAfter(w http.ResponseWriter, err error) {
+	var retryErr *RetryAfterError
+	if errors.As(err, &retryErr) {
+		seconds := int(math.Ceil(retryErr.RetryAfter.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	httpx.WriteError(w, http.StatusTooManyRequests, err)
+}
+
+// writePasswordRejected reports a refused new password against the password field, which is
+// named the same in every request that sets one.
+func writePasswordRejected(w http.ResponseWriter, err error) {
+	httpx.WriteFieldErrors(w, http.StatusBadRequest, err, map[string]string{
+		"password": err.Error(),
+	})
+}
+
+// ForgotPasswordRequest is the expected JSON payload for requesting a password reset email.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ForgotPassword sends a reset link when the account exists and always answers the same way.
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.ForgotPassword(r.Context(), req.Email); err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusAccepted, map[string]any{
+		"status": "if an account exists for this email, a reset link has been sent",
+	})
+}
+
+// ResetPasswordRequest is the expected JSON payload for choosing a new password with a reset token.
+type ResetPasswordRequest struct {
+	Token           string `json:"token" validate:"required"`
+	Password        string `json:"password" validate:"required,strong-password"`
+	PasswordConfirm string `json:"passwordConfirm" validate:"required,eqfield=Password"`
+}
+
+// ResetPassword sets a new password from a valid reset token.
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidToken):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		case errors.Is(err, ErrWeakPassword), errors.Is(err, ErrBreachedPassword):
+			writePasswordRejected(w, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EnrollTOTPResponse carries the pending secret and its otpauth:// provisioning URI.
+type EnrollTOTPResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// EnrollTOTP starts two-factor enrollment for the authenticated user.
+func (h *Handler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	enrollment, err := h.svc.EnrollTOTP(r.Context(), ctxUser.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTwoFactorEnabled):
+			httpx.WriteError(w, http.StatusConflict, err)
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, EnrollTOTPResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	})
+}
+
+// ConfirmTOTPRequest is the expected JSON payload for confirming two-factor enrollment.
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+// ConfirmTOTPResponse lists the one-time recovery codes; they are only shown once.
+type ConfirmTOTPResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// ConfirmTOTP enables two-factor authentication after the first valid code.
+func (h *Handler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	var req ConfirmTOTPRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	codes, err := h.svc.ConfirmTOTP(r.Context(), ctxUser.ID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTwoFactorEnabled):
+			httpx.WriteError(w, http.StatusConflict, err)
+		case errors.Is(err, ErrTwoFactorDisabled), errors.Is(err, ErrInvalidTwoFactor):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, ConfirmTOTPResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTPRequest is the expected JSON payload for turning two-factor authentication off.
+type DisableTOTPRequest struct {
+	Password string `json:"password" validate:"required,min=8,max=64"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+// DisableTOTP turns two-factor authentication off for the authenticated user.
+func (h *Handler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	var req DisableTOTPRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.DisableTOTP(r.Context(), ctxUser.ID, req.Password, req.Code); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCredentials), errors.Is(err, ErrInvalidTwoFactor):
+			httpx.WriteError(w, http.StatusUnauthorized, err)
+		case errors.Is(err, ErrTwoFactorDisabled):
+			httpx.WriteError(w, http.StatusConflict, err)
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SessionResponse is the public view of a signed-in device.
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// ListSessions returns the devices the authenticated user is signed in on.
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	sessions, err := h.svc.ListSessions(r.Context(), ctxUser.ID)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			Current:    session.ID == ctxUser.SessionID,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+		})
+	}
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"sessions": resp,
+	})
+}
+
+// RevokeSession signs the authenticated user out of one device.
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	if err := h.svc.RevokeSession(r.Context(), ctxUser.ID, chi.URLParam(r, "id")); err != nil {
+		switch {
+		case errors.Is(err, ErrSessionNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SecurityEventResponse is the public view of an audit log entry.
+type SecurityEventResponse struct {
+	ID        int64          `json:"id"`
+	Type      string         `json:"type"`
+	IP        string         `json:"ip"`
+	UserAgent string         `json:"user_agent"`
+	RequestID string         `json:"request_id"`
+	Metadata  map[string]any `json:"metadata"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func newSecurityEventResponse(event AuthEvent) SecurityEventResponse {
+	return SecurityEventResponse{
+		ID:        event.ID,
+		Type:      event.Type,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		RequestID: event.RequestID,
+		Metadata:  event.Metadata,
+		CreatedAt: event.CreatedAt,
+	}
+}
+
+// ListSecurityEvents returns a page of the authenticated user's sign-ins and other security events.
+func (h *Handler) ListSecurityEvents(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	input, err := parseListUsersInput(r)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	events, err := h.svc.ListSecurityEvents(r.Context(), ctxUser.ID, input)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := make([]SecurityEventResponse, 0, len(events))
+	for _, event := range events {
+		resp = append(resp, newSecurityEventResponse(event))
+	}
+
+	normalized := NormalizeListUsersInput(input)
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"count":  len(resp),
+		"limit":  normalized.Limit,
+		"offset": normalized.Offset,
+		"events": resp,
+	})
+}
+
+// CreatePersonalAccessTokenRequest is the expected JSON payload for creating a personal access token.
+type CreatePersonalAccessTokenRequest struct {
+	Name      string    `json:"name" validate:"required,max=100"`
+	Scopes    []string  `json:"scopes" validate:"required,min=1,unique,dive,oneof=posts:read posts:write"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// PersonalAccessTokenResponse is the public view of a personal access token. Token is only
+// populated in the response to creation.
+type PersonalAccessTokenResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// newPersonalAccessTokenResponse converts a token into its public view, rendering unset times as null.
+func newPersonalAccessTokenResponse(token PersonalAccessToken) PersonalAccessTokenResponse {
+	resp := PersonalAccessTokenResponse{
+		ID:        token.ID,
+		Name:      token.Name,
+		Prefix:    token.Prefix,
+		Scopes:    token.Scopes,
+		CreatedAt: token.CreatedAt,
+	}
+	if !token.ExpiresAt.IsZero() {
+		resp.ExpiresAt = &token.ExpiresAt
+	}
+	if !token.LastUsedAt.IsZero() {
+		resp.LastUsedAt = &token.LastUsedAt
+	}
+	return resp
+}
+
+// CreatePersonalAccessToken creates a scoped token for the authenticated user.
+func (h *Handler) CreatePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	var req CreatePersonalAccessTokenRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	token, raw, err := h.svc.CreatePersonalAccessToken(r.Context(), CreatePersonalAccessTokenInput{
+		UserID:    ctxUser.ID,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidExpiry):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	resp := newPersonalAccessTokenResponse(token)
+	resp.Token = raw
+	httpx.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// ListPersonalAccessTokens lists the authenticated user's active personal access tokens.
+func (h *Handler) ListPersonalAccessTokens(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	tokens, err := h.svc.ListPersonalAccessTokens(r.Context(), ctxUser.ID)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := make([]PersonalAccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		resp = append(resp, newPersonalAccessTokenResponse(token))
+	}
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"tokens": resp,
+	})
+}
+
+// RevokePersonalAccessToken revokes one of the authenticated user's personal access tokens.
+func (h *Handler) RevokePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.RevokePersonalAccessToken(r.Context(), ctxUser.ID, id); err != nil {
+		switch {
+		case errors.Is(err, ErrTokenNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateInviteRequest is the expected JSON payload for creating an invite code. MaxUses defaults to
+// a single use.
+type CreateInviteRequest struct {
+	Email     string    `json:"email" validate:"omitempty,email"`
+	MaxUses   int32     `json:"maxUses" validate:"omitempty,min=1,max=1000"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// InviteResponse is the public view of an invite. Code is only populated in the response to creation.
+type InviteResponse struct {
+	ID        int64      `json:"id"`
+	Code      string     `json:"code,omitempty"`
+	Prefix    string     `json:"prefix"`
+	Email     *string    `json:"email"`
+	MaxUses   int32      `json:"max_uses"`
+	UseCount  int32      `json:"use_count"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// newInviteResponse converts an invite into its public view, rendering unset fields as null.
+func newInviteResponse(invite Invite) InviteResponse {
+	resp := InviteResponse{
+		ID:        invite.ID,
+		Prefix:    invite.Prefix,
+		MaxUses:   invite.MaxUses,
+		UseCount:  invite.UseCount,
+		CreatedAt: invite.CreatedAt,
+	}
+	if invite.Email != "" {
+		resp.Email = &invite.Email
+	}
+	if !invite.ExpiresAt.IsZero() {
+		resp.ExpiresAt = &invite.ExpiresAt
+	}
+	return resp
+}
+
+// CreateInvite creates an invite code on behalf of the authenticated user.
+func (h *Handler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	var req CreateInviteRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+
+	invite, code, err := h.svc.CreateInvite(r.Context(), CreateInviteInput{
+		CreatedBy: ctxUser.ID,
+		Email:     req.Email,
+		MaxUses:   req.MaxUses,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidExpiry):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	resp := newInviteResponse(invite)
+	resp.Code = code
+	httpx.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// ListInvites lists the invites the authenticated user created.
+func (h *Handler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	invites, err := h.svc.ListInvites(r.Context(), ctxUser.ID)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := make([]InviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		resp = append(resp, newInviteResponse(invite))
+	}
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"invites": resp,
+	})
+}
+
+// RevokeInvite revokes one of the authenticated user's invites.
+func (h *Handler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.RevokeInvite(r.Context(), ctxUser.ID, id); err != nil {
+		switch {
+		case errors.Is(err, ErrInviteNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminUserResponse is the admin view of an account.
+type AdminUserResponse struct {
+	ID               int64     `json:"id"`
+	Email            string    `json:"email"`
+	Username         string    `json:"username"`
+	Role             Role      `json:"role"`
+	IsActive         bool      `json:"is_active"`
+	EmailVerified    bool      `json:"email_verified"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// ListUsersResponse is the JSON response body for the admin user listing.
+type ListUsersResponse struct {
+	Count  int                 `json:"count"`
+	Limit  int32               `json:"limit"`
+	Offset int32               `json:"offset"`
+	Users  []AdminUserResponse `json:"users"`
+}
+
+// ListUsers handles paginated admin requests to list all accounts.
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	input, err := parseListUsersInput(r)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	users, err := h.svc.ListUsers(r.Context(), input)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := make([]AdminUserResponse, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, AdminUserResponse{
+			ID:               u.ID,
+			Email:            u.Email,
+			Username:         u.Username,
+			Role:             u.Role,
+			IsActive:         u.IsActive,
+			EmailVerified:    !u.EmailVerifiedAt.IsZero(),
+			TwoFactorEnabled: !u.TOTPEnabledAt.IsZero(),
+			CreatedAt:        u.CreatedAt,
+			UpdatedAt:        u.UpdatedAt,
+		})
+	}
+
+	normalized := NormalizeListUsersInput(input)
+	httpx.WriteJSON(w, http.StatusOK, ListUsersResponse{
+		Users:  resp,
+		Count:  len(resp),
+		Limit:  normalized.Limit,
+		Offset: normalized.Offset,
+	})
+}
+
+// parseListUsersInput reads the optional limit and offset query parameters.
+func parseListUsersInput(r *http.Request) (ListUsersInput, error) {
+	limitStr := r.URL.Query().Get("limit")
+	offsetStr := r.URL.Query().Get("offset")
+
+	var limit, offset int64
+	var err error
+
+	if limitStr != "" {
+		limit, err = strconv.ParseInt(limitStr, 10, 32)
+		if err != nil {
+			return ListUsersInput{}, errors.New("invalid limit parameter")
+		}
+	}
+
+	if offsetStr != "" {
+		offset, err = strconv.ParseInt(offsetStr, 10, 32)
+		if err != nil {
+			return ListUsersInput{}, errors.New("invalid offset parameter")
+		}
+	}
+
+	return ListUsersInput{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	}, nil
+}
+
+// DeactivateUser disables an account and signs it out everywhere.
+func (h *Handler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.DeactivateUser(r.Context(), ctxUser.ID, id); err != nil {
+		switch {
+		case errors.Is(err, ErrCannotModifySelf):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnlockUser clears a sign-in lockout so the account can try again immediately.
+func (h *Handler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.UnlockUser(r.Context(), ctxUser.ID, id); err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminAuthEventResponse is the admin view of an audit log entry. UserID is null for failed
+// sign-ins with an unknown email.
+type AdminAuthEventResponse struct {
+	UserID *int64 `json:"user_id"`
+	SecurityEventResponse
+}
+
+// SearchAuthEvents handles admin queries over the audit log of all users. Every filter is optional;
+// since and until take RFC 3339 timestamps and bound created_at as a half-open range.
+func (h *Handler) SearchAuthEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuthEventFilter(r)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	events, err := h.svc.SearchAuthEvents(r.Context(), filter)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := make([]AdminAuthEventResponse, 0, len(events))
+	for _, event := range events {
+		item := AdminAuthEventResponse{SecurityEventResponse: newSecurityEventResponse(event)}
+		if event.UserID != 0 {
+			item.UserID = &event.UserID
+		}
+		resp = append(resp, item)
+	}
+
+	normalized := NormalizeListUsersInput(ListUsersInput{Limit: filter.Limit, Offset: filter.Offset})
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"count":  len(resp),
+		"limit":  normalized.Limit,
+		"offset": normalized.Offset,
+		"events": resp,
+	})
+}
+
+// parseAuthEventFilter reads the audit log filters and the limit and offset query parameters.
+func parseAuthEventFilter(r *http.Request) (AuthEventFilter, error) {
+	page, err := parseListUsersInput(r)
+	if err != nil {
+		return AuthEventFilter{}, err
+	}
+
+	query := r.URL.Query()
+	filter := AuthEventFilter{
+		Type:      query.Get("type"),
+		IP:        query.Get("ip"),
+		RequestID: query.Get("request_id"),
+		Limit:     page.Limit,
+		Offset:    page.Offset,
+	}
+
+	if userID := query.Get("user_id"); userID != "" {
+		filter.UserID, err = strconv.ParseInt(userID, 10, 64)
+		if err != nil {
+			return AuthEventFilter{}, errors.New("invalid user_id parameter")
+		}
+	}
+	if since := query.Get("since"); since != "" {
+		filter.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			return AuthEventFilter{}, errors.New("invalid since parameter")
+		}
+	}
+	if until := query.Get("until"); until != "" {
+		filter.Until, err = time.Parse(time.RFC3339, until)
+		if err != nil {
+			return AuthEventFilter{}, errors.New("invalid until parameter")
+		}
+	}
+
+	return filter, nil
+}
+
+// SetUserRoleRequest is the expected JSON payload for changing a user's role.
+type SetUserRoleRequest struct {
+	Role Role `json:"role" validate:"required,oneof=admin editor author reader"`
+}
+
+// SetUserRole changes the role of an account.
+func (h *Handler) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	ctxUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req SetUserRoleRequest
+
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.SetUserRole(r.Context(), ctxUser.ID, id, req.Role); err != nil {
+		switch {
+		case errors.Is(err, ErrCannotModifySelf), errors.Is(err, ErrInvalidRole):
+			httpx.WriteError(w, http.StatusBadRequest, err)
+		case errors.Is(err, ErrUserNotFound):
+			httpx.WriteError(w, http.StatusNotFound, err)
+		default:
+			httpx.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Routes registers user HTTP routes under the provided chi router.
+func (h *Handler) Routes(r chi.Router) chi.Router {
+	// Register user auth endpoints on the provided router.
+	r.Post("/signup", h.SignUp)
+	r.Post("/signin", h.SignIn)
+	r.Post("/signin/2fa", h.SignInTwoFactor)
+	r.Post("/signin/magic", h.RequestMagicLink)
+	r.Get("/signin/magic/callback", h.MagicLinkCallback)
+	r.Post("/token/refresh", h.RefreshToken)
+	r.Post("/password/forgot", h.ForgotPassword)
+	r.Post("/password/reset", h.ResetPassword)
+	r.Get("/verify", h.VerifyEmail)
+	r.Post("/verify/resend", h.ResendVerification)
+	r.Get("/email/confirm", h.ConfirmEmailChange)
+	r.Get("/deletion/cancel", h.CancelAccountDeletion)
+	r.With(h.OptionalAuthMiddleware).Get("/{username}", h.GetPublicProfile)
+	r.With(h.OptionalAuthMiddleware).Get("/{username}/followers", h.ListFollowers)
+	r.With(h.OptionalAuthMiddleware).Get("/{username}/following", h.ListFollowing)
+	r.Group(func(r chi.Router) {
+		r.Use(h.AuthMiddleware)
+		r.Use(RequireScope(ScopeAccount))
+		r.Get("/me", h.GetMe)
+		r.Patch("/me", h.UpdateMe)
+		r.Delete("/me", h.DeleteAccount)
+		r.Get("/me/export", h.ExportAccount)
+		r.Post("/me/password", h.ChangePassword)
+		r.Post("/me/email", h.ChangeEmail)
+		r.Post("/me/logout-all", h.LogoutAll)
+		r.Get("/me/sessions", h.ListSessions)
+		r.Delete("/me/sessions/{id}", h.RevokeSession)
+		r.Get("/me/security-events", h.ListSecurityEvents)
+		r.Post("/me/2fa/enroll", h.EnrollTOTP)
+		r.Post("/me/2fa/confirm", h.ConfirmTOTP)
+		r.Post("/me/2fa/disable", h.DisableTOTP)
+		r.Post("/me/tokens", h.CreatePersonalAccessToken)
+		r.Get("/me/tokens", h.ListPersonalAccessTokens)
+		r.Delete("/me/tokens/{id}", h.RevokePersonalAccessToken)
+		r.Post("/{username}/follow", h.FollowUser)
+		r.Delete("/{username}/follow", h.UnfollowUser)
+		r.Get("/me/blocks", h.ListBlockedUsers)
+		r.Get("/me/mutes", h.ListMutedUsers)
+		r.Post("/{username}/block", h.BlockUser)
+		r.Delete("/{username}/block", h.UnblockUser)
+		r.Post("/{username}/mute", h.MuteUser)
+		r.Delete("/{username}/mute", h.UnmuteUser)
+		r.Group(func(r chi.Router) {
+			r.Use(RequirePermission(PermInvitesCreate))
+			r.Post("/me/invites", h.CreateInvite)
+			r.Get("/me/invites", h.ListInvites)
+			r.Delete("/me/invites/{id}", h.RevokeInvite)
+		})
+	})
+	return r
+}
+
+// AdminRoutes registers administrator-only user management routes under the provided chi router.
+func (h *Handler) AdminRoutes(r chi.Router) chi.Router {
+	r.Group(func(r chi.Router) {
+		r.Use(h.AuthMiddleware)
+		r.Use(RequireScope(ScopeAccount))
+		r.Use(RequirePermission(PermUsersManage))
+		r.Get("/users", h.ListUsers)
+		r.Post("/users/{id}/deactivate", h.DeactivateUser)
+		r.Post("/users/{id}/unlock", h.UnlockUser)
+		r.Put("/users/{id}/role", h.SetUserRole)
+		r.Get("/auth-events", h.SearchAuthEvents)
 	})
 	return r
 }
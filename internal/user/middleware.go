@@ -2,12 +2,12 @@ package user
 
 import (
 	"context"
+	"errors"
 	"net/http"
-	"os"
+	"slices"
 	"strings"
 
 	"github.com/OnatArslan/devlog/internal/httpx"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 // ctxKey is a private type used to prevent context key collisions.
@@ -19,6 +19,21 @@ var authUserKey ctxKey = "auth_user"
 type AuthUser struct {
 	ID    int64
 	Email string
+	Role  Role
+	// Scopes lists what a personal access token may do. It is nil for interactive sessions,
+	// which are allowed everything.
+	Scopes []string
+	// SessionID identifies the interactive session the access token belongs to. It is empty
+	// for personal access tokens.
+	SessionID string
+}
+
+// HasScope reports whether the authenticated caller may act with the given scope.
+func (u AuthUser) HasScope(scope string) bool {
+	if u.Scopes == nil {
+		return true
+	}
+	return slices.Contains(u.Scopes, scope)
 }
 
 // AuthUserFromContext returns authenticated user data if middleware populated it.
@@ -27,52 +42,128 @@ func AuthUserFromContext(ctx context.Context) (AuthUser, bool) {
 	return user, ok
 }
 
-// AuthMiddleware validates Bearer JWT tokens and injects auth user data into context.
+// viewerID returns the ID of the signed-in caller, or zero for anonymous requests.
+func viewerID(r *http.Request) int64 {
+	authUser, ok := AuthUserFromContext(r.Context())
+	if !ok {
+		return 0
+	}
+	return authUser.ID
+}
+
+// AuthMiddleware validates Bearer JWTs or personal access tokens and injects auth user data into context.
 func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Read and validate the Authorization header format.
-		auth := r.Header.Get("Authorization")
-		if auth == "" || !strings.HasPrefix(auth, "Bearer ") {
-			httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		authUser, err := h.authenticate(r)
+		if err != nil {
+			if isAuthFailure(err) {
+				httpx.WriteError(w, http.StatusUnauthorized, err)
+				return
+			}
+			httpx.WriteError(w, http.StatusInternalServerError, err)
 			return
 		}
 
-		// Strip "Bearer " prefix and trim trailing/leading spaces.
-		tokenStr := strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
-		if tokenStr == "" {
-			httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
-			return
-		}
+		ctx := context.WithValue(r.Context(), authUserKey, authUser)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
 
-		// Ensure JWT secret exists before attempting signature verification.
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			httpx.WriteError(w, http.StatusInternalServerError, ErrJWTSecretNotSet)
+// OptionalAuthMiddleware authenticates the caller like AuthMiddleware when an Authorization header
+// is present and passes anonymous requests through untouched. Public routes use it to tailor their
+// responses to a signed-in reader, so a missing, malformed, expired or revoked token leaves the
+// caller anonymous instead of failing the request.
+func (h *Handler) OptionalAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		// Parse and validate token signature and standard claims into custom claims.
-		claims := CustomClaims{}
-
-		token, err := jwt.ParseWithClaims(tokenStr, &claims, func(t *jwt.Token) (any, error) {
-			if t.Method != jwt.SigningMethodHS256 {
-				return nil, ErrInvalidToken
+		authUser, err := h.authenticate(r)
+		if err != nil {
+			if isAuthFailure(err) {
+				next.ServeHTTP(w, r)
+				return
 			}
-			return []byte(secret), nil
-		})
-
-		if err != nil || !token.Valid {
-			httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidToken)
+			httpx.WriteError(w, http.StatusInternalServerError, err)
 			return
 		}
 
-		// Build context-safe auth payload for downstream protected handlers.
-		authUser := AuthUser{
-			ID:    claims.UserID,
-			Email: claims.Email,
-		}
-
 		ctx := context.WithValue(r.Context(), authUserKey, authUser)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// authenticate resolves the request's Bearer credentials to the authenticated user. Credentials that
+// are missing or no longer valid yield an error for which isAuthFailure reports true.
+func (h *Handler) authenticate(r *http.Request) (AuthUser, error) {
+	// Read and validate the Authorization header format.
+	auth := r.Header.Get("Authorization")
+	if auth == "" || !strings.HasPrefix(auth, "Bearer ") {
+		return AuthUser{}, ErrInvalidCredentials
+	}
+
+	// Strip "Bearer " prefix and trim trailing/leading spaces.
+	tokenStr := strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	if tokenStr == "" {
+		return AuthUser{}, ErrInvalidCredentials
+	}
+
+	// Personal access tokens are opaque and resolved through the database.
+	if strings.HasPrefix(tokenStr, personalAccessTokenPrefix) {
+		return h.svc.AuthenticatePersonalAccessToken(r.Context(), tokenStr)
+	}
+
+	// Parse and validate token signature and standard claims into custom claims.
+	claims := CustomClaims{}
+	if err := h.svc.parseToken(tokenStr, audienceAccess, &claims); err != nil {
+		return AuthUser{}, ErrInvalidToken
+	}
+	if claims.IssuedAt == nil || claims.SessionID == "" {
+		return AuthUser{}, ErrInvalidToken
+	}
+
+	// Reject tokens issued before the user's token_invalid_before cutoff.
+	if err := h.svc.CheckTokenIssuedAt(r.Context(), claims.UserID, claims.IssuedAt.Time); err != nil {
+		return AuthUser{}, err
+	}
+
+	// Reject tokens whose session was signed out from another device.
+	if err := h.svc.CheckSession(r.Context(), claims.UserID, claims.SessionID); err != nil {
+		return AuthUser{}, err
+	}
+
+	// Build context-safe auth payload for downstream protected handlers.
+	return AuthUser{
+		ID:        claims.UserID,
+		Email:     claims.Email,
+		Role:      claims.Role,
+		SessionID: claims.SessionID,
+	}, nil
+}
+
+// isAuthFailure reports whether err means the caller's credentials were rejected, as opposed to the
+// server failing to check them.
+func isAuthFailure(err error) bool {
+	return errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrInvalidToken) ||
+		errors.Is(err, ErrTokenRevoked)
+}
+
+// RequireScope rejects callers whose credentials do not carry scope. It must run after AuthMiddleware.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authUser, ok := AuthUserFromContext(r.Context())
+			if !ok {
+				httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+				return
+			}
+			if !authUser.HasScope(scope) {
+				httpx.WriteError(w, http.StatusForbidden, ErrInsufficientScope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
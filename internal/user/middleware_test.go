@@ -0,0 +1,63 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOptionalAuthMiddlewareFallsBackToAnonymous(t *testing.T) {
+	svc, _ := newTestService(t)
+	h := NewUserHandler(svc, nil)
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "no header"},
+		{name: "not bearer", header: "Basic dXNlcjpwYXNz"},
+		{name: "empty token", header: "Bearer "},
+		{name: "malformed token", header: "Bearer not-a-jwt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			var viewer int64 = -1
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				viewer = viewerID(r)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/users/jane", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.OptionalAuthMiddleware(next).ServeHTTP(rec, req)
+
+			if !called {
+				t.Fatalf("handler not called, status %d", rec.Code)
+			}
+			if viewer != 0 {
+				t.Errorf("viewerID = %d, want 0", viewer)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareRejectsMalformedToken(t *testing.T) {
+	svc, _ := newTestService(t)
+	h := NewUserHandler(svc, nil)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler called for a malformed token")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+	req.Header.Set("Authorization", "Bearer not-a-jwt")
+	rec := httptest.NewRecorder()
+	h.AuthMiddleware(next).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,217 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/OnatArslan/devlog/internal/passcheck"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnsupportedHash is returned for stored password hashes in a format no hasher understands.
+var ErrUnsupportedHash = errors.New("unsupported password hash format")
+
+// PasswordHasher derives and checks stored password hashes.
+//
+// Every implementation verifies all formats the application has ever written, so switching the
+// configured hasher never locks existing users out. NeedsRehash reports hashes that were written
+// by another algorithm or with weaker parameters; they are upgraded on the user's next sign-in.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(encoded, password string) (bool, error)
+	NeedsRehash(encoded string) bool
+}
+
+// Argon2idParams tunes the cost of argon2id. Memory is in KiB.
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams follow the OWASP recommendation with extra memory headroom.
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Argon2idHasher writes PHC-formatted argon2id hashes:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+type Argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher returns an argon2id hasher using params for new hashes.
+func NewArgon2idHasher(params Argon2idParams) *Argon2idHasher {
+	return &Argon2idHasher{params: params}
+}
+
+// Hash derives a new argon2id hash with a random salt.
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return encodeArgon2id(h.params, salt, key), nil
+}
+
+// Verify checks password against a hash in any supported format.
+func (h *Argon2idHasher) Verify(encoded, password string) (bool, error) {
+	return verifyPassword(encoded, password)
+}
+
+// NeedsRehash reports hashes that are not argon2id with exactly the configured parameters.
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params != h.params
+}
+
+// BcryptHasher writes standard $2a$ bcrypt hashes. It exists for deployments that cannot afford
+// argon2id's memory cost and to keep verifying hashes created before argon2id was introduced.
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher returns a bcrypt hasher using cost for new hashes.
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+// Hash derives a new bcrypt hash.
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Verify checks password against a hash in any supported format.
+func (h *BcryptHasher) Verify(encoded, password string) (bool, error) {
+	return verifyPassword(encoded, password)
+}
+
+// NeedsRehash reports hashes that are not bcrypt with exactly the configured cost.
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.cost
+}
+
+// verifyPassword dispatches on the hash's algorithm identifier. It returns false without an error
+// on a mismatch and ErrUnsupportedHash for unknown or malformed hashes.
+func verifyPassword(encoded, password string) (bool, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false, err
+		}
+		derived := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(derived, key) == 1, nil
+
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrUnsupportedHash, err)
+		}
+		return true, nil
+
+	default:
+		return false, ErrUnsupportedHash
+	}
+}
+
+// encodeArgon2id renders an argon2id hash in PHC string format with unpadded base64 fields.
+func encodeArgon2id(params Argon2idParams, salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+}
+
+// decodeArgon2id parses a PHC argon2id string. SaltLength and KeyLength are left unset in the
+// returned params; the caller can read them off salt and key.
+func decodeArgon2id(encoded string) (Argon2idParams, []byte, []byte, error) {
+	// "$argon2id$v=19$m=...,t=...,p=...$salt$key" splits into 6 parts with a leading empty one.
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2idParams{}, nil, nil, ErrUnsupportedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2idParams{}, nil, nil, ErrUnsupportedHash
+	}
+
+	var params Argon2idParams
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2idParams{}, nil, nil, ErrUnsupportedHash
+	}
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return Argon2idParams{}, nil, nil, ErrUnsupportedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2idParams{}, nil, nil, ErrUnsupportedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2idParams{}, nil, nil, ErrUnsupportedHash
+	}
+
+	return params, salt, key, nil
+}
+
+// minPasswordScore is the lowest passcheck score accepted for a new password. Score 3 means
+// roughly 10^8 guesses, which holds up against online attacks and slows down offline ones.
+const minPasswordScore = 3
+
+// screenPassword rejects new passwords that are easy to guess or known from a breach.
+// userInputs such as the email and username count as the first words an attacker would try.
+func (s *Service) screenPassword(ctx context.Context, password string, userInputs ...string) error {
+	strength := passcheck.Estimate(password, userInputs...)
+	if strength.Score < minPasswordScore {
+		reason := "password is too easy to guess"
+		if strength.Warning != "" {
+			reason += ": " + strength.Warning
+		}
+		return &PasswordRejectedError{Err: ErrWeakPassword, Reason: reason}
+	}
+
+	if s.breaches == nil {
+		return nil
+	}
+	breached, err := s.breaches.Breached(ctx, password)
+	if err != nil {
+		return fmt.Errorf("screen password: %w", err)
+	}
+	if breached {
+		return &PasswordRejectedError{
+			Err:    ErrBreachedPassword,
+			Reason: "password has appeared in a data breach, please choose another one",
+		}
+	}
+	return nil
+}
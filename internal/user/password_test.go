@@ -0,0 +1,176 @@
+package user
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2idParams keep the tests fast; production uses DefaultArgon2idParams.
+var testArgon2idParams = Argon2idParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestArgon2idHasherRoundTrip(t *testing.T) {
+	h := NewArgon2idHasher(testArgon2idParams)
+
+	encoded, err := h.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("Hash = %q, want a PHC argon2id string with the configured parameters", encoded)
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"correct horse battery staple", true},
+		{"correct horse battery stapler", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		got, err := h.Verify(encoded, tt.password)
+		if err != nil {
+			t.Fatalf("Verify(%q): %v", tt.password, err)
+		}
+		if got != tt.want {
+			t.Errorf("Verify(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+
+	if h.NeedsRehash(encoded) {
+		t.Error("NeedsRehash on a hash with the configured parameters = true")
+	}
+}
+
+func TestArgon2idHashesAreSalted(t *testing.T) {
+	h := NewArgon2idHasher(testArgon2idParams)
+	a, err := h.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := h.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Error("two hashes of the same password are identical")
+	}
+}
+
+func TestEncodeDecodeArgon2id(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	key := []byte("0123456789abcdef0123456789abcdef")
+	encoded := encodeArgon2id(testArgon2idParams, salt, key)
+
+	params, gotSalt, gotKey, err := decodeArgon2id(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gotSalt) != string(salt) || string(gotKey) != string(key) {
+		t.Errorf("decodeArgon2id(%q) returned salt %q and key %q", encoded, gotSalt, gotKey)
+	}
+	want := testArgon2idParams
+	want.SaltLength, want.KeyLength = 0, 0
+	if params != want {
+		t.Errorf("decodeArgon2id params = %+v, want %+v", params, want)
+	}
+}
+
+func TestDecodeArgon2idRejectsMalformed(t *testing.T) {
+	for _, encoded := range []string{
+		"",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA",
+	} {
+		if _, _, _, err := decodeArgon2id(encoded); !errors.Is(err, ErrUnsupportedHash) {
+			t.Errorf("decodeArgon2id(%q) error = %v, want ErrUnsupportedHash", encoded, err)
+		}
+	}
+}
+
+func TestVerifyPasswordAcrossFormats(t *testing.T) {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("hunter22"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	argonHash, err := NewArgon2idHasher(testArgon2idParams).Hash("hunter22")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		want     bool
+		wantErr  error
+	}{
+		{"bcrypt match", string(bcryptHash), "hunter22", true, nil},
+		{"bcrypt mismatch", string(bcryptHash), "hunter23", false, nil},
+		{"argon2id match", argonHash, "hunter22", true, nil},
+		{"argon2id mismatch", argonHash, "hunter23", false, nil},
+		{"unknown format", "plaintext", "plaintext", false, ErrUnsupportedHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Both hashers must verify every format.
+			for _, h := range []PasswordHasher{NewArgon2idHasher(testArgon2idParams), NewBcryptHasher(bcrypt.MinCost)} {
+				got, err := h.Verify(tt.encoded, tt.password)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("%T.Verify error = %v, want %v", h, err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("%T.Verify = %v, want %v", h, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	argon := NewArgon2idHasher(testArgon2idParams)
+	argonHash, err := argon.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stronger := testArgon2idParams
+	stronger.Iterations++
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		hasher  PasswordHasher
+		encoded string
+		want    bool
+	}{
+		{"argon2id current", argon, argonHash, false},
+		{"argon2id weaker params", NewArgon2idHasher(stronger), argonHash, true},
+		{"bcrypt to argon2id", argon, string(bcryptHash), true},
+		{"bcrypt current", NewBcryptHasher(bcrypt.MinCost), string(bcryptHash), false},
+		{"bcrypt other cost", NewBcryptHasher(bcrypt.MinCost + 1), string(bcryptHash), true},
+		{"argon2id to bcrypt", NewBcryptHasher(bcrypt.MinCost), argonHash, true},
+	}
+	for _, tt := range tests {
+		if got := tt.hasher.NeedsRehash(tt.encoded); got != tt.want {
+			t.Errorf("%s: NeedsRehash = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package user
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/OnatArslan/devlog/internal/httpx"
+)
+
+// Role is the access level assigned to a user account.
+type Role string
+
+// Roles from most to least privileged.
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleAuthor Role = "author"
+	RoleReader Role = "reader"
+)
+
+// Permission names a single action that roles may be allowed to perform.
+type Permission string
+
+// Permissions checked by handlers and route middleware.
+const (
+	PermPostsCreate   Permission = "posts:create"
+	PermPostsEditAny  Permission = "posts:edit_any"
+	PermUsersManage   Permission = "users:manage"
+	PermInvitesCreate Permission = "invites:create"
+)
+
+// rolePermissions maps each role to what it may do. Authors may always change their own posts;
+// PermPostsEditAny extends that to posts written by anyone.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin:  {PermPostsCreate, PermPostsEditAny, PermUsersManage, PermInvitesCreate},
+	RoleEditor: {PermPostsCreate, PermPostsEditAny, PermInvitesCreate},
+	RoleAuthor: {PermPostsCreate},
+	RoleReader: {},
+}
+
+// Valid reports whether r is one of the known roles.
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether the role grants p.
+func (r Role) Can(p Permission) bool {
+	return slices.Contains(rolePermissions[r], p)
+}
+
+// Can reports whether the authenticated caller's role grants p.
+func (u AuthUser) Can(p Permission) bool {
+	return u.Role.Can(p)
+}
+
+// RequireRole rejects callers whose role is not one of roles. It must run after AuthMiddleware.
+func RequireRole(roles ...Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authUser, ok := AuthUserFromContext(r.Context())
+			if !ok {
+				httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+				return
+			}
+			if !slices.Contains(roles, authUser.Role) {
+				httpx.WriteError(w, http.StatusForbidden, ErrForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePermission rejects callers whose role does not grant p. It must run after AuthMiddleware.
+func RequirePermission(p Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authUser, ok := AuthUserFromContext(r.Context())
+			if !ok {
+				httpx.WriteError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+				return
+			}
+			if !authUser.Can(p) {
+				httpx.WriteError(w, http.StatusForbidden, ErrForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
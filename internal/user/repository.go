@@ -2,25 +2,32 @@ package user
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/OnatArslan/devlog/internal/sqlc"
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Repository provides user persistence operations backed by sqlc queries.
 type Repository struct {
-	q *sqlc.Queries
+	db *pgxpool.Pool
+	q  *sqlc.Queries
 }
 
 // NewUserRepository wires repository methods to generated sqlc query implementations.
-func NewUserRepository(q *sqlc.Queries) *Repository {
+// The pool is kept as well for the operations that must run in a transaction.
+func NewUserRepository(db *pgxpool.Pool) *Repository {
 	// Return a repository instance backed by generated sqlc queries.
 	return &Repository{
-		q: q,
+		db: db,
+		q:  sqlc.New(db),
 	}
 }
 
@@ -41,22 +48,850 @@ func (r *Repository) CreateUser(ctx context.Context, input CreateUserParams) (Us
 	})
 
 	if err != nil {
-		// Translate known Postgres unique violations into domain-level conflicts.
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-			switch pgErr.ConstraintName {
-			case "users_email_key":
-				return User{}, ErrEmailTaken
-			case "users_username_key":
-				return User{}, ErrUsernameTaken
-			default:
-				return User{}, ErrConflict
-			}
+		if conflict := userConflict(err); conflict != nil {
+			return User{}, conflict
 		}
 		return User{}, err
 	}
 
 	// Map sqlc row fields into the package domain model.
+	return userFromRow(row), nil
+}
+
+// CreateUserWithInvite consumes one use of the invite with codeHash and inserts the user in the
+// same transaction, so a failed insert never burns the invite and a used-up invite never creates
+// a user. Unknown, expired, revoked, exhausted, or mismatched invites yield ErrInvalidInvite.
+func (r *Repository) CreateUserWithInvite(ctx context.Context, input CreateUserParams, codeHash string) (User, int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return User{}, 0, fmt.Errorf("repository create user with invite begin: %w", err)
+	}
+	// Rollback is a no-op once the transaction has been committed.
+	defer tx.Rollback(ctx)
+
+	qtx := r.q.WithTx(tx)
+	inviteID, err := qtx.ConsumeInvite(ctx, sqlc.ConsumeInviteParams{
+		CodeHash: codeHash,
+		Email:    input.Email,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, 0, ErrInvalidInvite
+		}
+		return User{}, 0, fmt.Errorf("repository consume invite: %w", err)
+	}
+
+	row, err := qtx.CreateUser(ctx, sqlc.CreateUserParams{
+		Email:        input.Email,
+		Username:     input.Username,
+		PasswordHash: input.PasswordHash,
+	})
+	if err != nil {
+		if conflict := userConflict(err); conflict != nil {
+			return User{}, 0, conflict
+		}
+		return User{}, 0, fmt.Errorf("repository create user with invite: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return User{}, 0, fmt.Errorf("repository create user with invite commit: %w", err)
+	}
+	return userFromRow(row), inviteID, nil
+}
+
+// userConflict translates Postgres unique violations on users into domain-level conflicts.
+// It returns nil for any other error.
+func userConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgerrcode.UniqueViolation {
+		return nil
+	}
+
+	switch pgErr.ConstraintName {
+	case "users_email_key":
+		return ErrEmailTaken
+	case "users_username_key":
+		return ErrUsernameTaken
+	default:
+		return ErrConflict
+	}
+}
+
+// GetByEmail returns an active user by email or a domain not-found error.
+func (r *Repository) GetByEmail(ctx context.Context, email string) (User, error) {
+	// Query one active user by email from the database.
+	row, err := r.q.GetByEmail(ctx, email)
+	if err != nil {
+		// Convert no-row result into a domain not-found error.
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, ErrUserNotFound
+		}
+		return User{}, fmt.Errorf("repository get by email: %w", err)
+	}
+
+	// Map query result into the package domain model.
+	return userFromRow(row), nil
+}
+
+// GetAnyByEmail returns a user by email regardless of activation state.
+func (r *Repository) GetAnyByEmail(ctx context.Context, email string) (User, error) {
+	row, err := r.q.GetAnyByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, ErrUserNotFound
+		}
+		return User{}, fmt.Errorf("repository get any by email: %w", err)
+	}
+
+	return userFromRow(row), nil
+}
+
+// GetAnyByID returns a user by ID regardless of activation state.
+func (r *Repository) GetAnyByID(ctx context.Context, id int64) (User, error) {
+	row, err := r.q.GetAnyByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, ErrUserNotFound
+		}
+		return User{}, fmt.Errorf("repository get any by id: %w", err)
+	}
+
+	return userFromRow(row), nil
+}
+
+// GetByID returns an active user by ID or a domain not-found error.
+func (r *Repository) GetByID(ctx context.Context, id int64) (User, error) {
+	row, err := r.q.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, ErrUserNotFound
+		}
+		return User{}, fmt.Errorf("repository get by id: %w", err)
+	}
+
+	return userFromRow(row), nil
+}
+
+// GetTokenInvalidBefore returns the token cutoff of an active user or a domain not-found error.
+func (r *Repository) GetTokenInvalidBefore(ctx context.Context, id int64) (time.Time, error) {
+	ts, err := r.q.GetTokenInvalidBefore(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, ErrUserNotFound
+		}
+		return time.Time{}, fmt.Errorf("repository get token invalid before: %w", err)
+	}
+	return ts.Time, nil
+}
+
+// InvalidateUserTokens moves the user's token cutoff to cutoff.
+func (r *Repository) InvalidateUserTokens(ctx context.Context, id int64, cutoff time.Time) error {
+	n, err := r.q.InvalidateUserTokens(ctx, sqlc.InvalidateUserTokensParams{
+		Cutoff: pgtype.Timestamptz{Time: cutoff, Valid: true},
+		ID:     id,
+	})
+	if err != nil {
+		return fmt.Errorf("repository invalidate user tokens: %w", err)
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// VerifyEmail activates an unverified account whose email still matches the verified address.
+// It returns ErrInvalidToken when the account is already verified or the email has changed.
+func (r *Repository) VerifyEmail(ctx context.Context, id int64, email string) error {
+	n, err := r.q.VerifyEmail(ctx, sqlc.VerifyEmailParams{
+		ID:    id,
+		Email: email,
+	})
+	if err != nil {
+		return fmt.Errorf("repository verify email: %w", err)
+	}
+	if n == 0 {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// MarkVerificationSent records a verification email unless one was sent after notBefore.
+// It reports whether the caller won the right to send.
+func (r *Repository) MarkVerificationSent(ctx context.Context, id int64, notBefore time.Time) (bool, error) {
+	n, err := r.q.MarkVerificationSent(ctx, sqlc.MarkVerificationSentParams{
+		ID:                 id,
+		VerificationSentAt: pgtype.Timestamptz{Time: notBefore, Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("repository mark verification sent: %w", err)
+	}
+	return n == 1, nil
+}
+
+// ReleaseVerificationSent frees the send slot claimed by MarkVerificationSent after a failed send.
+func (r *Repository) ReleaseVerificationSent(ctx context.Context, id int64) error {
+	if err := r.q.ReleaseVerificationSent(ctx, id); err != nil {
+		return fmt.Errorf("repository release verification sent: %w", err)
+	}
+	return nil
+}
+
+// ListUsers returns a page of users ordered by ID, including inactive accounts.
+func (r *Repository) ListUsers(ctx context.Context, limit, offset int32) ([]User, error) {
+	rows, err := r.q.ListUsers(ctx, sqlc.ListUsersParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository list users: %w", err)
+	}
+
+	users := make([]User, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, userFromRow(row))
+	}
+	return users, nil
+}
+
+// DeactivateUser marks an active account inactive or returns ErrUserNotFound.
+func (r *Repository) DeactivateUser(ctx context.Context, id int64) error {
+	n, err := r.q.DeactivateUser(ctx, id)
+	if err != nil {
+		return fmt.Errorf("repository deactivate user: %w", err)
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// ScheduleAccountDeletion deactivates an active account and records when it may be purged and the
+// hash of the nonce its restore link carries, in one transaction so an account is never left
+// inactive without a pending deletion or the other way round.
+func (r *Repository) ScheduleAccountDeletion(ctx context.Context, userID int64, purgeAfter time.Time, restoreTokenHash string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository schedule account deletion begin: %w", err)
+	}
+	// Rollback is a no-op once the transaction has been committed.
+	defer tx.Rollback(ctx)
+
+	qtx := r.q.WithTx(tx)
+	n, err := qtx.DeactivateUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("repository schedule account deletion: %w", err)
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+
+	err = qtx.ScheduleAccountDeletion(ctx, sqlc.ScheduleAccountDeletionParams{
+		UserID:           userID,
+		PurgeAfter:       pgtype.Timestamptz{Time: purgeAfter, Valid: true},
+		RestoreTokenHash: pgtype.Text{String: restoreTokenHash, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("repository schedule account deletion: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository schedule account deletion commit: %w", err)
+	}
+	return nil
+}
+
+// CancelAccountDeletion drops a pending deletion that is still within its grace period and
+// reactivates the account. restoreTokenHash must match the hash stored with the deletion; nil only
+// matches deletions scheduled before restore links carried a nonce. It returns ErrInvalidToken when
+// there is nothing left to cancel.
+func (r *Repository) CancelAccountDeletion(ctx context.Context, userID int64, restoreTokenHash *string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository cancel account deletion begin: %w", err)
+	}
+	// Rollback is a no-op once the transaction has been committed.
+	defer tx.Rollback(ctx)
+
+	qtx := r.q.WithTx(tx)
+	n, err := qtx.CancelAccountDeletion(ctx, sqlc.CancelAccountDeletionParams{
+		UserID:           userID,
+		RestoreTokenHash: optionalText(restoreTokenHash),
+	})
+	if err != nil {
+		return fmt.Errorf("repository cancel account deletion: %w", err)
+	}
+	if n == 0 {
+		return ErrInvalidToken
+	}
+
+	if _, err := qtx.ReactivateUser(ctx, userID); err != nil {
+		return fmt.Errorf("repository cancel account deletion: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository cancel account deletion commit: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeletedAccounts hard-deletes every account whose deletion grace period is over and
+// returns how many were removed.
+func (r *Repository) PurgeDeletedAccounts(ctx context.Context) (int64, error) {
+	n, err := r.q.PurgeDeletedAccounts(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("repository purge deleted accounts: %w", err)
+	}
+	return n, nil
+}
+
+// ListAuthoredPosts returns every post the user wrote, oldest first.
+func (r *Repository) ListAuthoredPosts(ctx context.Context, userID int64) ([]AuthoredPost, error) {
+	rows, err := r.q.ListAuthorPosts(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repository list authored posts: %w", err)
+	}
+
+	posts := make([]AuthoredPost, 0, len(rows))
+	for _, row := range rows {
+		posts = append(posts, AuthoredPost{
+			ID:          row.ID,
+			Title:       row.Title,
+			Slug:        row.Slug,
+			Content:     row.Content,
+			Status:      row.Status,
+			PublishedAt: row.PublishedAt.Time,
+			CreatedAt:   row.CreatedAt.Time,
+			UpdatedAt:   row.UpdatedAt.Time,
+		})
+	}
+	return posts, nil
+}
+
+// SetUserRole changes the role of a user or returns ErrUserNotFound.
+func (r *Repository) SetUserRole(ctx context.Context, id int64, role Role) error {
+	n, err := r.q.SetUserRole(ctx, sqlc.SetUserRoleParams{
+		ID:   id,
+		Role: string(role),
+	})
+	if err != nil {
+		return fmt.Errorf("repository set user role: %w", err)
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdatePasswordHash replaces the stored password hash of a user.
+func (r *Repository) UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error {
+	err := r.q.UpdatePasswordHash(ctx, sqlc.UpdatePasswordHashParams{
+		ID:           id,
+		PasswordHash: passwordHash,
+	})
+	if err != nil {
+		return fmt.Errorf("repository update password hash: %w", err)
+	}
+	return nil
+}
+
+// UpdateProfileParams lists profile changes. Nil fields are left untouched.
+type UpdateProfileParams struct {
+	Username    *string
+	DisplayName *string
+	Bio         *string
+	Website     *string
+	SocialLinks map[string]string
+}
+
+// UpdateProfile applies profile changes to an active user, failing with ErrUsernameTaken when a
+// new username is already in use.
+func (r *Repository) UpdateProfile(ctx context.Context, id int64, input UpdateProfileParams) error {
+	var socialLinks []byte
+	if input.SocialLinks != nil {
+		var err error
+		socialLinks, err = json.Marshal(input.SocialLinks)
+		if err != nil {
+			return fmt.Errorf("repository update profile: %w", err)
+		}
+	}
+
+	n, err := r.q.UpdateProfile(ctx, sqlc.UpdateProfileParams{
+		Username:    optionalText(input.Username),
+		DisplayName: optionalText(input.DisplayName),
+		Bio:         optionalText(input.Bio),
+		Website:     optionalText(input.Website),
+		SocialLinks: socialLinks,
+		ID:          id,
+	})
+	if err != nil {
+		if conflict := userConflict(err); conflict != nil {
+			return conflict
+		}
+		return fmt.Errorf("repository update profile: %w", err)
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// optionalText maps an optional string onto a nullable text parameter.
+func optionalText(s *string) pgtype.Text {
+	if s == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *s, Valid: true}
+}
+
+// GetPublicProfile returns the public profile of an active user by username. Users who blocked
+// viewerID are reported as not found.
+func (r *Repository) GetPublicProfile(ctx context.Context, username string, viewerID int64) (PublicProfile, error) {
+	row, err := r.q.GetPublicProfile(ctx, sqlc.GetPublicProfileParams{
+		Username: username,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PublicProfile{}, ErrUserNotFound
+		}
+		return PublicProfile{}, fmt.Errorf("repository get public profile: %w", err)
+	}
+
+	return PublicProfile{
+		Username:       row.Username,
+		DisplayName:    row.DisplayName,
+		Bio:            row.Bio,
+		Website:        row.Website,
+		SocialLinks:    decodeSocialLinks(row.SocialLinks),
+		PostCount:      row.PostCount,
+		FollowerCount:  row.FollowerCount,
+		FollowingCount: row.FollowingCount,
+		JoinedAt:       row.CreatedAt.Time,
+	}, nil
+}
+
+// GetIDByUsername resolves an active user's username to their ID.
+func (r *Repository) GetIDByUsername(ctx context.Context, username string) (int64, error) {
+	id, err := r.q.GetIDByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrUserNotFound
+		}
+		return 0, fmt.Errorf("repository get id by username: %w", err)
+	}
+	return id, nil
+}
+
+// GetVisibleIDByUsername resolves the username of an active user who has not blocked viewerID.
+func (r *Repository) GetVisibleIDByUsername(ctx context.Context, username string, viewerID int64) (int64, error) {
+	id, err := r.q.GetVisibleIDByUsername(ctx, sqlc.GetVisibleIDByUsernameParams{
+		Username: username,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrUserNotFound
+		}
+		return 0, fmt.Errorf("repository get visible id by username: %w", err)
+	}
+	return id, nil
+}
+
+// GetAnyIDByUsername resolves a username to its user ID whether or not the account is active.
+func (r *Repository) GetAnyIDByUsername(ctx context.Context, username string) (int64, error) {
+	id, err := r.q.GetAnyIDByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrUserNotFound
+		}
+		return 0, fmt.Errorf("repository get any id by username: %w", err)
+	}
+	return id, nil
+}
+
+// FollowUser makes followerID follow followeeID. Following someone twice is a no-op.
+func (r *Repository) FollowUser(ctx context.Context, followerID, followeeID int64) error {
+	err := r.q.FollowUser(ctx, sqlc.FollowUserParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		return fmt.Errorf("repository follow user: %w", err)
+	}
+	return nil
+}
+
+// UnfollowUser removes the follow from followerID to followeeID, if there is one.
+func (r *Repository) UnfollowUser(ctx context.Context, followerID, followeeID int64) error {
+	err := r.q.UnfollowUser(ctx, sqlc.UnfollowUserParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		return fmt.Errorf("repository unfollow user: %w", err)
+	}
+	return nil
+}
+
+// BlockUser records that blockerID blocks blockedID and removes any follow between the two in
+// either direction, so neither keeps seeing the other in their feed.
+func (r *Repository) BlockUser(ctx context.Context, blockerID, blockedID int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository block user begin: %w", err)
+	}
+	// Rollback is a no-op once the transaction has been committed.
+	defer tx.Rollback(ctx)
+
+	qtx := r.q.WithTx(tx)
+	if err := qtx.BlockUser(ctx, sqlc.BlockUserParams{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+	}); err != nil {
+		return fmt.Errorf("repository block user: %w", err)
+	}
+	if err := qtx.DeleteFollowsBetween(ctx, sqlc.DeleteFollowsBetweenParams{
+		UserID:  blockerID,
+		OtherID: blockedID,
+	}); err != nil {
+		return fmt.Errorf("repository block user: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository block user commit: %w", err)
+	}
+	return nil
+}
+
+// UnblockUser removes the block from blockerID on blockedID, if there is one.
+func (r *Repository) UnblockUser(ctx context.Context, blockerID, blockedID int64) error {
+	err := r.q.UnblockUser(ctx, sqlc.UnblockUserParams{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+	})
+	if err != nil {
+		return fmt.Errorf("repository unblock user: %w", err)
+	}
+	return nil
+}
+
+// IsBlockedBetween reports whether either user has blocked the other.
+func (r *Repository) IsBlockedBetween(ctx context.Context, userID, otherID int64) (bool, error) {
+	blocked, err := r.q.IsBlockedBetween(ctx, sqlc.IsBlockedBetweenParams{
+		UserID:  userID,
+		OtherID: otherID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("repository is blocked between: %w", err)
+	}
+	return blocked, nil
+}
+
+// ListBlockedUsers returns a page of the accounts userID has blocked, most recent first.
+func (r *Repository) ListBlockedUsers(ctx context.Context, userID int64, limit, offset int32) ([]RelationEntry, error) {
+	rows, err := r.q.ListBlockedUsers(ctx, sqlc.ListBlockedUsersParams{
+		BlockerID: userID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository list blocked users: %w", err)
+	}
+
+	entries := make([]RelationEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, RelationEntry{
+			Username:    row.Username,
+			DisplayName: row.DisplayName,
+			CreatedAt:   row.CreatedAt.Time,
+		})
+	}
+	return entries, nil
+}
+
+// MuteUser records that muterID mutes mutedID. Muting an already muted user is a no-op.
+func (r *Repository) MuteUser(ctx context.Context, muterID, mutedID int64) error {
+	err := r.q.MuteUser(ctx, sqlc.MuteUserParams{
+		MuterID: muterID,
+		MutedID: mutedID,
+	})
+	if err != nil {
+		return fmt.Errorf("repository mute user: %w", err)
+	}
+	return nil
+}
+
+// UnmuteUser removes the mute from muterID on mutedID, if there is one.
+func (r *Repository) UnmuteUser(ctx context.Context, muterID, mutedID int64) error {
+	err := r.q.UnmuteUser(ctx, sqlc.UnmuteUserParams{
+		MuterID: muterID,
+		MutedID: mutedID,
+	})
+	if err != nil {
+		return fmt.Errorf("repository unmute user: %w", err)
+	}
+	return nil
+}
+
+// ListMutedUsers returns a page of the accounts userID has muted, most recent first.
+func (r *Repository) ListMutedUsers(ctx context.Context, userID int64, limit, offset int32) ([]RelationEntry, error) {
+	rows, err := r.q.ListMutedUsers(ctx, sqlc.ListMutedUsersParams{
+		MuterID: userID,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository list muted users: %w", err)
+	}
+
+	entries := make([]RelationEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, RelationEntry{
+			Username:    row.Username,
+			DisplayName: row.DisplayName,
+			CreatedAt:   row.CreatedAt.Time,
+		})
+	}
+	return entries, nil
+}
+
+// ListFollowers returns a page of the active accounts following userID, most recent first. Accounts that
+// blocked viewerID are left out.
+func (r *Repository) ListFollowers(ctx context.Context, userID, viewerID int64, limit, offset int32) ([]FollowEntry, error) {
+	rows, err := r.q.ListFollowers(ctx, sqlc.ListFollowersParams{
+		FolloweeID: userID,
+		ViewerID:   viewerID,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository list followers: %w", err)
+	}
+
+	entries := make([]FollowEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, FollowEntry{
+			Username:    row.Username,
+			DisplayName: row.DisplayName,
+			FollowedAt:  row.FollowedAt.Time,
+		})
+	}
+	return entries, nil
+}
+
+// ListFollowing returns a page of the active accounts userID follows, most recent first. Accounts that
+// blocked viewerID are left out.
+func (r *Repository) ListFollowing(ctx context.Context, userID, viewerID int64, limit, offset int32) ([]FollowEntry, error) {
+	rows, err := r.q.ListFollowing(ctx, sqlc.ListFollowingParams{
+		FollowerID: userID,
+		ViewerID:   viewerID,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository list following: %w", err)
+	}
+
+	entries := make([]FollowEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, FollowEntry{
+			Username:    row.Username,
+			DisplayName: row.DisplayName,
+			FollowedAt:  row.FollowedAt.Time,
+		})
+	}
+	return entries, nil
+}
+
+// CountFollowers counts the active accounts following userID that have not blocked viewerID.
+func (r *Repository) CountFollowers(ctx context.Context, userID, viewerID int64) (int64, error) {
+	n, err := r.q.CountFollowers(ctx, sqlc.CountFollowersParams{
+		FolloweeID: userID,
+		ViewerID:   viewerID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("repository count followers: %w", err)
+	}
+	return n, nil
+}
+
+// CountFollowing counts the active accounts userID follows that have not blocked viewerID.
+func (r *Repository) CountFollowing(ctx context.Context, userID, viewerID int64) (int64, error) {
+	n, err := r.q.CountFollowing(ctx, sqlc.CountFollowingParams{
+		FollowerID: userID,
+		ViewerID:   viewerID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("repository count following: %w", err)
+	}
+	return n, nil
+}
+
+// decodeSocialLinks decodes the social_links column. The column is always a JSON object, so a
+// decoding failure only happens on corrupt data and yields no links rather than an error.
+func decodeSocialLinks(raw []byte) map[string]string {
+	links := map[string]string{}
+	_ = json.Unmarshal(raw, &links)
+	return links
+}
+
+// UpdateEmail moves an active user from currentEmail to newEmail and marks the new address verified.
+// It fails with ErrInvalidToken when the account no longer has currentEmail and with ErrEmailTaken
+// when another account already owns newEmail.
+func (r *Repository) UpdateEmail(ctx context.Context, id int64, currentEmail, newEmail string) error {
+	n, err := r.q.UpdateEmail(ctx, sqlc.UpdateEmailParams{
+		NewEmail:     newEmail,
+		ID:           id,
+		CurrentEmail: currentEmail,
+	})
+	if err != nil {
+		if conflict := userConflict(err); conflict != nil {
+			return conflict
+		}
+		return fmt.Errorf("repository update email: %w", err)
+	}
+	if n == 0 {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// RehashPassword replaces oldHash with newHash unless the password changed since oldHash was read.
+func (r *Repository) RehashPassword(ctx context.Context, id int64, oldHash, newHash string) error {
+	err := r.q.RehashPassword(ctx, sqlc.RehashPasswordParams{
+		NewHash: newHash,
+		ID:      id,
+		OldHash: oldHash,
+	})
+	if err != nil {
+		return fmt.Errorf("repository rehash password: %w", err)
+	}
+	return nil
+}
+
+// SetTOTPSecret stores a pending TOTP secret, failing with ErrTwoFactorEnabled if 2FA is already on.
+func (r *Repository) SetTOTPSecret(ctx context.Context, id int64, secret string) error {
+	n, err := r.q.SetTOTPSecret(ctx, sqlc.SetTOTPSecretParams{
+		ID:         id,
+		TotpSecret: pgtype.Text{String: secret, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("repository set totp secret: %w", err)
+	}
+	if n == 0 {
+		return ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+// EnableTOTP activates the pending secret and records the step of the confirming code.
+func (r *Repository) EnableTOTP(ctx context.Context, id int64, step int64) error {
+	n, err := r.q.EnableTOTP(ctx, sqlc.EnableTOTPParams{
+		ID:           id,
+		TotpLastStep: step,
+	})
+	if err != nil {
+		return fmt.Errorf("repository enable totp: %w", err)
+	}
+	if n == 0 {
+		return ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+// DisableTOTP removes the TOTP secret and turns two-factor authentication off.
+func (r *Repository) DisableTOTP(ctx context.Context, id int64) error {
+	if err := r.q.DisableTOTP(ctx, id); err != nil {
+		return fmt.Errorf("repository disable totp: %w", err)
+	}
+	return nil
+}
+
+// UseTOTPStep records step as used and reports false when it, or a later step, was already used.
+func (r *Repository) UseTOTPStep(ctx context.Context, id int64, step int64) (bool, error) {
+	n, err := r.q.UseTOTPStep(ctx, sqlc.UseTOTPStepParams{
+		ID:           id,
+		TotpLastStep: step,
+	})
+	if err != nil {
+		return false, fmt.Errorf("repository use totp step: %w", err)
+	}
+	return n == 1, nil
+}
+
+// CreateRefreshTokenParams defines the input fields required to persist a refresh token.
+type CreateRefreshTokenParams struct {
+	UserID    int64
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+// CreateRefreshToken stores the hash of a newly issued refresh token.
+func (r *Repository) CreateRefreshToken(ctx context.Context, input CreateRefreshTokenParams) (RefreshToken, error) {
+	var familyID pgtype.UUID
+	if err := familyID.Scan(input.FamilyID); err != nil {
+		return RefreshToken{}, fmt.Errorf("repository create refresh token: %w", err)
+	}
+
+	row, err := r.q.CreateRefreshToken(ctx, sqlc.CreateRefreshTokenParams{
+		UserID:    input.UserID,
+		FamilyID:  familyID,
+		TokenHash: input.TokenHash,
+		ExpiresAt: pgtype.Timestamptz{Time: input.ExpiresAt, Valid: true},
+	})
+	if err != nil {
+		return RefreshToken{}, fmt.Errorf("repository create refresh token: %w", err)
+	}
+
+	return refreshTokenFromRow(row), nil
+}
+
+// GetRefreshTokenByHash returns a refresh token by its hash or ErrInvalidToken when unknown.
+func (r *Repository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	row, err := r.q.GetRefreshTokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return RefreshToken{}, ErrInvalidToken
+		}
+		return RefreshToken{}, fmt.Errorf("repository get refresh token: %w", err)
+	}
+
+	return refreshTokenFromRow(row), nil
+}
+
+// RotateRefreshToken marks a token as used and reports whether this call was the one that claimed it.
+func (r *Repository) RotateRefreshToken(ctx context.Context, id int64) (bool, error) {
+	n, err := r.q.RotateRefreshToken(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("repository rotate refresh token: %w", err)
+	}
+	return n == 1, nil
+}
+
+// RevokeRefreshTokenFamily revokes every still-active token that belongs to the given family.
+func (r *Repository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	var id pgtype.UUID
+	if err := id.Scan(familyID); err != nil {
+		return fmt.Errorf("repository revoke refresh token family: %w", err)
+	}
+
+	if err := r.q.RevokeRefreshTokenFamily(ctx, id); err != nil {
+		return fmt.Errorf("repository revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes every still-active refresh token owned by the user.
+func (r *Repository) RevokeUserRefreshTokens(ctx context.Context, userID int64) error {
+	if err := r.q.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("repository revoke user refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// userFromRow maps a sqlc user row into the package domain model.
+func userFromRow(row sqlc.User) User {
 	return User{
 		ID:                 row.ID,
 		Email:              row.Email,
@@ -64,32 +899,627 @@ func (r *Repository) CreateUser(ctx context.Context, input CreateUserParams) (Us
 		PasswordHash:       row.PasswordHash,
 		IsActive:           row.IsActive,
 		TokenInvalidBefore: row.TokenInvalidBefore.Time,
+		EmailVerifiedAt:    row.EmailVerifiedAt.Time,
+		VerificationSentAt: row.VerificationSentAt.Time,
+		TOTPSecret:         row.TotpSecret.String,
+		TOTPEnabledAt:      row.TotpEnabledAt.Time,
+		TOTPLastStep:       row.TotpLastStep,
+		Role:               Role(row.Role),
+		DisplayName:        row.DisplayName,
+		Bio:                row.Bio,
+		Website:            row.Website,
+		SocialLinks:        decodeSocialLinks(row.SocialLinks),
 		CreatedAt:          row.CreatedAt.Time,
 		UpdatedAt:          row.UpdatedAt.Time,
+	}
+}
+
+// refreshTokenFromRow maps a sqlc refresh token row into the package domain model.
+func refreshTokenFromRow(row sqlc.RefreshToken) RefreshToken {
+	return RefreshToken{
+		ID:        row.ID,
+		UserID:    row.UserID,
+		FamilyID:  row.FamilyID.String(),
+		TokenHash: row.TokenHash,
+		ExpiresAt: row.ExpiresAt.Time,
+		RotatedAt: row.RotatedAt.Time,
+		RevokedAt: row.RevokedAt.Time,
+		CreatedAt: row.CreatedAt.Time,
+	}
+}
+
+// CreateSessionParams defines the input fields required to record a new sign-in session.
+type CreateSessionParams struct {
+	ID        string
+	UserID    int64
+	UserAgent string
+	IP        string
+}
+
+// CreateSession records a session for a new refresh token family.
+func (r *Repository) CreateSession(ctx context.Context, input CreateSessionParams) (Session, error) {
+	var id pgtype.UUID
+	if err := id.Scan(input.ID); err != nil {
+		return Session{}, fmt.Errorf("repository create session: %w", err)
+	}
+
+	row, err := r.q.CreateSession(ctx, sqlc.CreateSessionParams{
+		ID:        id,
+		UserID:    input.UserID,
+		UserAgent: input.UserAgent,
+		Ip:        input.IP,
+	})
+	if err != nil {
+		return Session{}, fmt.Errorf("repository create session: %w", err)
+	}
+
+	return sessionFromRow(row), nil
+}
+
+// GetSession returns a session by ID or ErrSessionNotFound when it does not exist.
+func (r *Repository) GetSession(ctx context.Context, sessionID string) (Session, error) {
+	var id pgtype.UUID
+	if err := id.Scan(sessionID); err != nil {
+		return Session{}, ErrSessionNotFound
+	}
+
+	row, err := r.q.GetSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Session{}, ErrSessionNotFound
+		}
+		return Session{}, fmt.Errorf("repository get session: %w", err)
+	}
+
+	return sessionFromRow(row), nil
+}
+
+// ListUserSessions returns the user's unrevoked sessions seen after activeSince, most recent first.
+func (r *Repository) ListUserSessions(ctx context.Context, userID int64, activeSince time.Time) ([]Session, error) {
+	rows, err := r.q.ListUserSessions(ctx, sqlc.ListUserSessionsParams{
+		UserID:     userID,
+		LastSeenAt: pgtype.Timestamptz{Time: activeSince, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository list user sessions: %w", err)
+	}
+
+	sessions := make([]Session, 0, len(rows))
+	for _, row := range rows {
+		sessions = append(sessions, sessionFromRow(row))
+	}
+	return sessions, nil
+}
+
+// TouchSession records activity on a session along with the client it came from.
+func (r *Repository) TouchSession(ctx context.Context, sessionID string, client ClientInfo) error {
+	var id pgtype.UUID
+	if err := id.Scan(sessionID); err != nil {
+		return fmt.Errorf("repository touch session: %w", err)
+	}
+
+	err := r.q.TouchSession(ctx, sqlc.TouchSessionParams{
+		ID:        id,
+		Ip:        client.IP,
+		UserAgent: client.UserAgent,
+	})
+	if err != nil {
+		return fmt.Errorf("repository touch session: %w", err)
+	}
+	return nil
+}
+
+// RevokeSession revokes one of the user's sessions and reports ErrSessionNotFound when it
+// does not exist, belongs to someone else, or was already revoked.
+func (r *Repository) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	var id pgtype.UUID
+	if err := id.Scan(sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+
+	n, err := r.q.RevokeSession(ctx, sqlc.RevokeSessionParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("repository revoke session: %w", err)
+	}
+	if n == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes every still-active session owned by the user and returns their IDs.
+func (r *Repository) RevokeUserSessions(ctx context.Context, userID int64) ([]string, error) {
+	ids, err := r.q.RevokeUserSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repository revoke user sessions: %w", err)
+	}
+	sessionIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		sessionIDs = append(sessionIDs, id.String())
+	}
+	return sessionIDs, nil
+}
+
+// sessionFromRow maps a sqlc session row into the package domain model.
+func sessionFromRow(row sqlc.Session) Session {
+	return Session{
+		ID:         row.ID.String(),
+		UserID:     row.UserID,
+		UserAgent:  row.UserAgent,
+		IP:         row.Ip,
+		CreatedAt:  row.CreatedAt.Time,
+		LastSeenAt: row.LastSeenAt.Time,
+		RevokedAt:  row.RevokedAt.Time,
+	}
+}
+
+// CreateMagicLinkToken stores the hash of a newly issued sign-in link and the client it was requested from.
+func (r *Repository) CreateMagicLinkToken(ctx context.Context, userID int64, tokenHash, clientHash string, expiresAt time.Time) error {
+	err := r.q.CreateMagicLinkToken(ctx, sqlc.CreateMagicLinkTokenParams{
+		UserID:     userID,
+		TokenHash:  tokenHash,
+		ClientHash: clientHash,
+		ExpiresAt:  pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("repository create magic link token: %w", err)
+	}
+	return nil
+}
+
+// GetMagicLinkTokenByHash returns a sign-in link token by its hash or ErrInvalidToken when unknown.
+func (r *Repository) GetMagicLinkTokenByHash(ctx context.Context, tokenHash string) (MagicLinkToken, error) {
+	row, err := r.q.GetMagicLinkTokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return MagicLinkToken{}, ErrInvalidToken
+		}
+		return MagicLinkToken{}, fmt.Errorf("repository get magic link token: %w", err)
+	}
+
+	return MagicLinkToken{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		TokenHash:  row.TokenHash,
+		ClientHash: row.ClientHash,
+		ExpiresAt:  row.ExpiresAt.Time,
+		UsedAt:     row.UsedAt.Time,
+		CreatedAt:  row.CreatedAt.Time,
 	}, nil
 }
 
-// GetByEmail returns an active user by email or a domain not-found error.
-func (r *Repository) GetByEmail(ctx context.Context, email string) (User, error) {
-	// Query one active user by email from the database.
-	row, err := r.q.GetByEmail(ctx, email)
+// UseMagicLinkToken marks a sign-in link as used and reports whether this call was the one that claimed it.
+func (r *Repository) UseMagicLinkToken(ctx context.Context, id int64) (bool, error) {
+	n, err := r.q.UseMagicLinkToken(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("repository use magic link token: %w", err)
+	}
+	return n == 1, nil
+}
+
+// CountRecentMagicLinkTokens counts the sign-in links issued to the user after since.
+func (r *Repository) CountRecentMagicLinkTokens(ctx context.Context, userID int64, since time.Time) (int64, error) {
+	n, err := r.q.CountRecentMagicLinkTokens(ctx, sqlc.CountRecentMagicLinkTokensParams{
+		UserID:    userID,
+		CreatedAt: pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("repository count magic link tokens: %w", err)
+	}
+	return n, nil
+}
+
+// CreatePasswordResetToken stores the hash of a newly issued password reset token.
+func (r *Repository) CreatePasswordResetToken(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	err := r.q.CreatePasswordResetToken(ctx, sqlc.CreatePasswordResetTokenParams{
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("repository create password reset token: %w", err)
+	}
+	return nil
+}
+
+// ConsumePasswordResetToken marks a valid reset token as used and returns its owner's ID.
+// Unknown, expired, or already used tokens yield ErrInvalidToken.
+func (r *Repository) ConsumePasswordResetToken(ctx context.Context, tokenHash string) (int64, error) {
+	userID, err := r.q.ConsumePasswordResetToken(ctx, tokenHash)
 	if err != nil {
-		// Convert no-row result into a domain not-found error.
 		if errors.Is(err, pgx.ErrNoRows) {
-			return User{}, ErrUserNotFound
+			return 0, ErrInvalidToken
 		}
-		return User{}, fmt.Errorf("repository get by email: %w", err)
+		return 0, fmt.Errorf("repository consume password reset token: %w", err)
 	}
+	return userID, nil
+}
 
-	// Map query result into the package domain model.
-	return User{
-		ID:                 row.ID,
-		Email:              row.Email,
-		Username:           row.Username,
-		PasswordHash:       row.PasswordHash,
-		IsActive:           row.IsActive,
-		TokenInvalidBefore: row.TokenInvalidBefore.Time,
-		CreatedAt:          row.CreatedAt.Time,
-		UpdatedAt:          row.UpdatedAt.Time,
+// DiscardPasswordResetTokens marks every outstanding reset token of the user as used.
+func (r *Repository) DiscardPasswordResetTokens(ctx context.Context, userID int64) error {
+	if err := r.q.DiscardPasswordResetTokens(ctx, userID); err != nil {
+		return fmt.Errorf("repository discard password reset tokens: %w", err)
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes and stores the given hashes instead.
+func (r *Repository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	if err := r.q.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("repository delete recovery codes: %w", err)
+	}
+
+	for _, codeHash := range codeHashes {
+		err := r.q.CreateRecoveryCode(ctx, sqlc.CreateRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: codeHash,
+		})
+		if err != nil {
+			return fmt.Errorf("repository create recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteRecoveryCodes removes every recovery code of the user.
+func (r *Repository) DeleteRecoveryCodes(ctx context.Context, userID int64) error {
+	if err := r.q.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("repository delete recovery codes: %w", err)
+	}
+	return nil
+}
+
+// UseRecoveryCode consumes an unused recovery code and reports whether it was valid.
+func (r *Repository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	n, err := r.q.UseRecoveryCode(ctx, sqlc.UseRecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: codeHash,
+	})
+	if err != nil {
+		return false, fmt.Errorf("repository use recovery code: %w", err)
+	}
+	return n == 1, nil
+}
+
+// CreatePersonalAccessTokenParams defines the input fields required to persist a personal access token.
+type CreatePersonalAccessTokenParams struct {
+	UserID    int64
+	Name      string
+	Prefix    string
+	TokenHash string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// CreatePersonalAccessToken stores a new personal access token. A zero ExpiresAt means it never expires.
+func (r *Repository) CreatePersonalAccessToken(ctx context.Context, input CreatePersonalAccessTokenParams) (PersonalAccessToken, error) {
+	row, err := r.q.CreatePersonalAccessToken(ctx, sqlc.CreatePersonalAccessTokenParams{
+		UserID:      input.UserID,
+		Name:        input.Name,
+		TokenPrefix: input.Prefix,
+		TokenHash:   input.TokenHash,
+		Scopes:      input.Scopes,
+		ExpiresAt:   pgtype.Timestamptz{Time: input.ExpiresAt, Valid: !input.ExpiresAt.IsZero()},
+	})
+	if err != nil {
+		return PersonalAccessToken{}, fmt.Errorf("repository create personal access token: %w", err)
+	}
+
+	return personalAccessTokenFromRow(row), nil
+}
+
+// ListPersonalAccessTokens returns the user's non-revoked personal access tokens, newest first.
+func (r *Repository) ListPersonalAccessTokens(ctx context.Context, userID int64) ([]PersonalAccessToken, error) {
+	rows, err := r.q.ListPersonalAccessTokens(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repository list personal access tokens: %w", err)
+	}
+
+	tokens := make([]PersonalAccessToken, 0, len(rows))
+	for _, row := range rows {
+		tokens = append(tokens, personalAccessTokenFromRow(row))
+	}
+	return tokens, nil
+}
+
+// RevokePersonalAccessToken revokes one of the user's tokens or returns ErrTokenNotFound.
+func (r *Repository) RevokePersonalAccessToken(ctx context.Context, userID, id int64) error {
+	n, err := r.q.RevokePersonalAccessToken(ctx, sqlc.RevokePersonalAccessTokenParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("repository revoke personal access token: %w", err)
+	}
+	if n == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+// PersonalAccessTokenOwner is a resolved personal access token together with its owner's identity.
+type PersonalAccessTokenOwner struct {
+	Token PersonalAccessToken
+	Email string
+	Role  Role
+}
+
+// GetActivePersonalAccessToken resolves a token hash to the token and its owner's identity.
+// Unknown, revoked, or expired tokens yield ErrInvalidToken.
+func (r *Repository) GetActivePersonalAccessToken(ctx context.Context, tokenHash string) (PersonalAccessTokenOwner, error) {
+	row, err := r.q.GetActivePersonalAccessToken(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PersonalAccessTokenOwner{}, ErrInvalidToken
+		}
+		return PersonalAccessTokenOwner{}, fmt.Errorf("repository get personal access token: %w", err)
+	}
+
+	return PersonalAccessTokenOwner{
+		Token: PersonalAccessToken{
+			ID:         row.ID,
+			UserID:     row.UserID,
+			Name:       row.Name,
+			Prefix:     row.TokenPrefix,
+			Scopes:     row.Scopes,
+			ExpiresAt:  row.ExpiresAt.Time,
+			LastUsedAt: row.LastUsedAt.Time,
+			RevokedAt:  row.RevokedAt.Time,
+			CreatedAt:  row.CreatedAt.Time,
+		},
+		Email: row.Email,
+		Role:  Role(row.Role),
 	}, nil
 }
+
+// TouchPersonalAccessToken records that the token was just used.
+func (r *Repository) TouchPersonalAccessToken(ctx context.Context, id int64) error {
+	if err := r.q.TouchPersonalAccessToken(ctx, id); err != nil {
+		return fmt.Errorf("repository touch personal access token: %w", err)
+	}
+	return nil
+}
+
+// personalAccessTokenFromRow maps a sqlc personal access token row into the package domain model.
+func personalAccessTokenFromRow(row sqlc.PersonalAccessToken) PersonalAccessToken {
+	return PersonalAccessToken{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		Name:       row.Name,
+		Prefix:     row.TokenPrefix,
+		Scopes:     row.Scopes,
+		ExpiresAt:  row.ExpiresAt.Time,
+		LastUsedAt: row.LastUsedAt.Time,
+		RevokedAt:  row.RevokedAt.Time,
+		CreatedAt:  row.CreatedAt.Time,
+	}
+}
+
+// CreateInviteParams defines the input fields required to persist an invite. An empty Email leaves
+// the invite open to any address and a zero ExpiresAt means it never expires.
+type CreateInviteParams struct {
+	CreatedBy int64
+	Prefix    string
+	CodeHash  string
+	Email     string
+	MaxUses   int32
+	ExpiresAt time.Time
+}
+
+// CreateInvite stores a new invite code.
+func (r *Repository) CreateInvite(ctx context.Context, input CreateInviteParams) (Invite, error) {
+	row, err := r.q.CreateInvite(ctx, sqlc.CreateInviteParams{
+		CreatedBy:  input.CreatedBy,
+		CodePrefix: input.Prefix,
+		CodeHash:   input.CodeHash,
+		Email:      pgtype.Text{String: input.Email, Valid: input.Email != ""},
+		MaxUses:    input.MaxUses,
+		ExpiresAt:  pgtype.Timestamptz{Time: input.ExpiresAt, Valid: !input.ExpiresAt.IsZero()},
+	})
+	if err != nil {
+		return Invite{}, fmt.Errorf("repository create invite: %w", err)
+	}
+
+	return inviteFromRow(row), nil
+}
+
+// ListInvitesByCreator returns the non-revoked invites a user created, newest first.
+func (r *Repository) ListInvitesByCreator(ctx context.Context, userID int64) ([]Invite, error) {
+	rows, err := r.q.ListInvitesByCreator(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repository list invites: %w", err)
+	}
+
+	invites := make([]Invite, 0, len(rows))
+	for _, row := range rows {
+		invites = append(invites, inviteFromRow(row))
+	}
+	return invites, nil
+}
+
+// RevokeInvite revokes one of the user's invites or returns ErrInviteNotFound.
+func (r *Repository) RevokeInvite(ctx context.Context, userID, id int64) error {
+	n, err := r.q.RevokeInvite(ctx, sqlc.RevokeInviteParams{
+		ID:        id,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("repository revoke invite: %w", err)
+	}
+	if n == 0 {
+		return ErrInviteNotFound
+	}
+	return nil
+}
+
+// inviteFromRow maps a sqlc invite row into the package domain model.
+func inviteFromRow(row sqlc.Invite) Invite {
+	return Invite{
+		ID:        row.ID,
+		CreatedBy: row.CreatedBy,
+		Prefix:    row.CodePrefix,
+		Email:     row.Email.String,
+		MaxUses:   row.MaxUses,
+		UseCount:  row.UseCount,
+		ExpiresAt: row.ExpiresAt.Time,
+		RevokedAt: row.RevokedAt.Time,
+		CreatedAt: row.CreatedAt.Time,
+	}
+}
+
+// GetAuthThrottles returns the throttle state of every key that has one.
+func (r *Repository) GetAuthThrottles(ctx context.Context, keys []string) ([]AuthThrottle, error) {
+	rows, err := r.q.GetAuthThrottles(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("repository get auth throttles: %w", err)
+	}
+
+	throttles := make([]AuthThrottle, 0, len(rows))
+	for _, row := range rows {
+		throttles = append(throttles, authThrottleFromRow(row))
+	}
+	return throttles, nil
+}
+
+// RecordAuthFailure counts a failed attempt for key, restarting streaks whose last failure is before resetBefore.
+func (r *Repository) RecordAuthFailure(ctx context.Context, key string, resetBefore time.Time) (AuthThrottle, error) {
+	row, err := r.q.RecordAuthFailure(ctx, sqlc.RecordAuthFailureParams{
+		Key:         key,
+		ResetBefore: pgtype.Timestamptz{Time: resetBefore, Valid: true},
+	})
+	if err != nil {
+		return AuthThrottle{}, fmt.Errorf("repository record auth failure: %w", err)
+	}
+	return authThrottleFromRow(row), nil
+}
+
+// LockAuthThrottle blocks key until the given time.
+func (r *Repository) LockAuthThrottle(ctx context.Context, key string, until time.Time) error {
+	err := r.q.LockAuthThrottle(ctx, sqlc.LockAuthThrottleParams{
+		Key:         key,
+		LockedUntil: pgtype.Timestamptz{Time: until, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("repository lock auth throttle: %w", err)
+	}
+	return nil
+}
+
+// ClearAuthThrottle removes the failure streak and any lock for key.
+func (r *Repository) ClearAuthThrottle(ctx context.Context, key string) error {
+	if err := r.q.ClearAuthThrottle(ctx, key); err != nil {
+		return fmt.Errorf("repository clear auth throttle: %w", err)
+	}
+	return nil
+}
+
+// authThrottleFromRow maps a sqlc auth throttle row into the package domain model.
+func authThrottleFromRow(row sqlc.AuthThrottle) AuthThrottle {
+	return AuthThrottle{
+		Key:           row.Key,
+		Failures:      int(row.Failures),
+		LastFailureAt: row.LastFailureAt.Time,
+		LockedUntil:   row.LockedUntil.Time,
+	}
+}
+
+// ListUserAuthEvents returns every audit log entry about the user, newest first.
+func (r *Repository) ListUserAuthEvents(ctx context.Context, userID int64) ([]AuthEvent, error) {
+	rows, err := r.q.ListUserAuthEvents(ctx, pgtype.Int8{Int64: userID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("repository list user auth events: %w", err)
+	}
+
+	events := make([]AuthEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, authEventFromRow(row))
+	}
+	return events, nil
+}
+
+// ListUserAuthEventsPage returns a page of audit log entries about the user, newest first.
+func (r *Repository) ListUserAuthEventsPage(ctx context.Context, userID int64, limit, offset int32) ([]AuthEvent, error) {
+	rows, err := r.q.ListUserAuthEventsPage(ctx, sqlc.ListUserAuthEventsPageParams{
+		UserID: pgtype.Int8{Int64: userID, Valid: true},
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository list user auth events page: %w", err)
+	}
+
+	events := make([]AuthEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, authEventFromRow(row))
+	}
+	return events, nil
+}
+
+// SearchAuthEvents returns a page of audit log entries across all users matching filter, newest first.
+func (r *Repository) SearchAuthEvents(ctx context.Context, filter AuthEventFilter) ([]AuthEvent, error) {
+	rows, err := r.q.SearchAuthEvents(ctx, sqlc.SearchAuthEventsParams{
+		UserID:     pgtype.Int8{Int64: filter.UserID, Valid: filter.UserID != 0},
+		EventType:  pgtype.Text{String: filter.Type, Valid: filter.Type != ""},
+		Ip:         pgtype.Text{String: filter.IP, Valid: filter.IP != ""},
+		RequestID:  pgtype.Text{String: filter.RequestID, Valid: filter.RequestID != ""},
+		Since:      pgtype.Timestamptz{Time: filter.Since, Valid: !filter.Since.IsZero()},
+		Until:      pgtype.Timestamptz{Time: filter.Until, Valid: !filter.Until.IsZero()},
+		PageLimit:  filter.Limit,
+		PageOffset: filter.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository search auth events: %w", err)
+	}
+
+	events := make([]AuthEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, authEventFromRow(row))
+	}
+	return events, nil
+}
+
+// authEventFromRow maps a sqlc auth event row into the package domain model. Metadata is always
+// a JSON object, so a decoding failure only happens on corrupt data and yields empty metadata.
+func authEventFromRow(row sqlc.AuthEvent) AuthEvent {
+	metadata := map[string]any{}
+	_ = json.Unmarshal(row.Metadata, &metadata)
+
+	return AuthEvent{
+		ID:        row.ID,
+		UserID:    row.UserID.Int64,
+		Type:      row.EventType,
+		IP:        row.Ip,
+		UserAgent: row.UserAgent,
+		RequestID: row.RequestID,
+		Metadata:  metadata,
+		CreatedAt: row.CreatedAt.Time,
+	}
+}
+
+// CreateAuthEvent appends an entry to the authentication audit log. A zero UserID is stored as NULL.
+func (r *Repository) CreateAuthEvent(ctx context.Context, event AuthEvent) error {
+	metadata := []byte("{}")
+	if len(event.Metadata) > 0 {
+		var err error
+		metadata, err = json.Marshal(event.Metadata)
+		if err != nil {
+			return fmt.Errorf("repository create auth event: %w", err)
+		}
+	}
+
+	err := r.q.CreateAuthEvent(ctx, sqlc.CreateAuthEventParams{
+		UserID:    pgtype.Int8{Int64: event.UserID, Valid: event.UserID != 0},
+		EventType: event.Type,
+		Ip:        event.IP,
+		UserAgent: event.UserAgent,
+		RequestID: event.RequestID,
+		Metadata:  metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("repository create auth event: %w", err)
+	}
+	return nil
+}
@@ -4,66 +4,238 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"log"
+	"math"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/OnatArslan/devlog/internal/keyring"
+	"github.com/OnatArslan/devlog/internal/mailer"
+	"github.com/OnatArslan/devlog/internal/passcheck"
+	"github.com/OnatArslan/devlog/internal/periodic"
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
+// tokenCutoffTTL bounds how long a cached token_invalid_before value is trusted.
+// Changes made through this process invalidate the cache immediately; other replicas
+// pick them up once their entry expires.
+const tokenCutoffTTL = 30 * time.Second
+
+// Config holds the collaborators and settings the user service needs beyond its repository.
+type Config struct {
+	// Mailer delivers account emails such as password reset links.
+	Mailer mailer.Mailer
+	// AppURL is the public base URL used to build links in emails, e.g. https://devlog.example.com.
+	AppURL string
+	// Keys signs and verifies every JWT the service issues.
+	Keys *keyring.Keyring
+	// PasswordHasher hashes new passwords. It defaults to argon2id with DefaultArgon2idParams.
+	// Outdated hashes are upgraded on sign-in. For accounts with two-factor authentication the
+	// upgrade is prepared in memory when the password is checked, so it only lands when the second
+	// step reaches the same replica; behind a load balancer without sticky sessions those accounts
+	// keep their old hash, which still verifies.
+	PasswordHasher PasswordHasher
+	// BreachChecker rejects passwords known from data breaches. Nil skips the breach check.
+	BreachChecker passcheck.BreachChecker
+	// RegistrationMode decides who may sign up. It defaults to RegistrationOpen.
+	RegistrationMode RegistrationMode
+	// DeletionGracePeriod is how long a deleted account can still be restored before it is
+	// purged. It defaults to defaultDeletionGracePeriod.
+	DeletionGracePeriod time.Duration
+}
+
 // Service contains business rules for user registration and authentication flows.
 type Service struct {
-	rep *Repository
+	rep           *Repository
+	mailer        mailer.Mailer
+	appURL        string
+	keys          *keyring.Keyring
+	hasher        PasswordHasher
+	breaches      passcheck.BreachChecker
+	registration  RegistrationMode
+	deletionGrace time.Duration
+	tokenCutoffs  *ttlCache[int64, time.Time]
+	sessions      *ttlCache[string, bool]
+	rehashes      *ttlCache[string, pendingRehash]
 }
 
-// NewUserService wires the service with its repository dependency.
-func NewUserService(rep *Repository) *Service {
+// NewUserService wires the service with its repository dependency and configuration.
+func NewUserService(rep *Repository, cfg Config) *Service {
+	if cfg.PasswordHasher == nil {
+		cfg.PasswordHasher = NewArgon2idHasher(DefaultArgon2idParams)
+	}
+	if cfg.RegistrationMode == "" {
+		cfg.RegistrationMode = RegistrationOpen
+	}
+	if cfg.DeletionGracePeriod <= 0 {
+		cfg.DeletionGracePeriod = defaultDeletionGracePeriod
+	}
+
 	// Return a service instance bound to the repository implementation.
 	return &Service{
-		rep: rep,
+		rep:           rep,
+		mailer:        cfg.Mailer,
+		appURL:        strings.TrimRight(cfg.AppURL, "/"),
+		keys:          cfg.Keys,
+		hasher:        cfg.PasswordHasher,
+		breaches:      cfg.BreachChecker,
+		registration:  cfg.RegistrationMode,
+		deletionGrace: cfg.DeletionGracePeriod,
+		tokenCutoffs:  newTTLCache[int64, time.Time](tokenCutoffTTL),
+		sessions:      newTTLCache[string, bool](tokenCutoffTTL),
+		rehashes:      newTTLCache[string, pendingRehash](twoFactorChallengeTTL),
 	}
 }
 
-// SignUpInput defines the fields required to register a new user.
+// SignUpInput defines the fields required to register a new user. InviteCode is only required
+// while registration is invite-only.
 type SignUpInput struct {
-	Email    string
-	Username string
-	Password string
+	Email      string
+	Username   string
+	Password   string
+	InviteCode string
 }
 
-// SignUp hashes the password and creates a new user record.
+// SignUp hashes the password, creates an unverified user record, and emails a verification link.
+// Depending on the registration mode it refuses signups outright or requires an invite code.
 func (s *Service) SignUp(ctx context.Context, input SignUpInput) (User, error) {
-	// Convert the plain password to bytes for bcrypt processing.
-	passwordByte := []byte(input.Password)
+	switch {
+	case s.registration == RegistrationClosed:
+		return User{}, ErrRegistrationClosed
+	case s.registration == RegistrationInviteOnly && input.InviteCode == "":
+		return User{}, ErrInviteRequired
+	}
+
+	if err := s.screenPassword(ctx, input.Password, input.Email, input.Username); err != nil {
+		return User{}, err
+	}
 
 	// Hash the password before persisting any user record.
-	hashedByte, err := bcrypt.GenerateFromPassword(passwordByte, 12)
+	passwordHash, err := s.hasher.Hash(input.Password)
 	if err != nil {
 		return User{}, err
 	}
-	// Persist the new user with the generated password hash.
-	user, err := s.rep.CreateUser(ctx, CreateUserParams{Email: input.Email,
-		PasswordHash: string(hashedByte),
-		Username:     input.Username})
+	// Persist the new user with the generated password hash, spending the invite if one was given.
+	params := CreateUserParams{Email: input.Email,
+		PasswordHash: passwordHash,
+		Username:     input.Username}
+
+	var user User
+	if input.InviteCode != "" {
+		var inviteID int64
+		user, inviteID, err = s.rep.CreateUserWithInvite(ctx, params, hashToken(input.InviteCode))
+		if err == nil {
+			s.recordEvent(ctx, user.ID, EventInviteRedeemed, map[string]any{"invite_id": inviteID})
+		}
+	} else {
+		user, err = s.rep.CreateUser(ctx, params)
+	}
 
 	if err != nil {
 		return User{}, fmt.Errorf("signup service : %w", err)
 	}
+	s.recordEvent(ctx, user.ID, EventSignUp, map[string]any{"invited": input.InviteCode != ""})
+
+	// The account stays inactive until the emailed link is opened. A failed send is not fatal
+	// because the user can ask for a new link through the resend endpoint right away.
+	if _, err := s.rep.MarkVerificationSent(ctx, user.ID, time.Now()); err != nil {
+		log.Printf("signup service mark verification sent: %v", err)
+	}
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("signup service send verification: %v", err)
+		if err := s.rep.ReleaseVerificationSent(context.WithoutCancel(ctx), user.ID); err != nil {
+			log.Printf("signup service release verification sent: %v", err)
+		}
+	}
 	return user, nil
 }
 
-// SignInOutput contains the authenticated user and generated access token metadata.
+// Token lifetimes for issued credentials.
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// checkPassword verifies password against the user's stored hash and returns ErrInvalidCredentials
+// on a mismatch. An unreadable stored hash is logged and treated as a mismatch, so callers cannot
+// tell a corrupt account apart from a wrong password.
+func (s *Service) checkPassword(user User, password string) error {
+	ok, err := s.hasher.Verify(user.PasswordHash, password)
+	if err != nil {
+		log.Printf("verify password for user %d: %v", user.ID, err)
+		return ErrInvalidCredentials
+	}
+	if !ok {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// rehashTimeout bounds the background upgrade of an outdated password hash.
+const rehashTimeout = 10 * time.Second
+
+// pendingRehash is an upgraded password hash waiting for its sign-in to complete.
+type pendingRehash struct {
+	userID  int64
+	oldHash string
+	newHash string
+}
+
+// rehashPassword upgrades an outdated hash to the configured algorithm and parameters. It runs
+// after the response is sent, so failures are only logged; the next sign-in simply tries again.
+// It deliberately leaves token_invalid_before alone because the password itself did not change.
+func (s *Service) rehashPassword(ctx context.Context, user User, password string) {
+	newHash, err := s.hasher.Hash(password)
+	if err != nil {
+		log.Printf("rehash password for user %d: %v", user.ID, err)
+		return
+	}
+	s.storeRehash(ctx, pendingRehash{userID: user.ID, oldHash: user.PasswordHash, newHash: newHash})
+}
+
+// prepareRehash computes the upgraded hash for a sign-in that still waits for its second factor
+// and keeps it under the challenge ID until SignInTwoFactor stores it. A challenge completed on
+// another replica, or before the hash is ready, leaves the upgrade to the next sign-in.
+func (s *Service) prepareRehash(user User, challengeID, password string) {
+	newHash, err := s.hasher.Hash(password)
+	if err != nil {
+		log.Printf("rehash password for user %d: %v", user.ID, err)
+		return
+	}
+	s.rehashes.Set(challengeID, pendingRehash{userID: user.ID, oldHash: user.PasswordHash, newHash: newHash})
+}
+
+// storeRehash swaps in an upgraded hash unless the password changed in the meantime.
+func (s *Service) storeRehash(ctx context.Context, rehash pendingRehash) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rehashTimeout)
+	defer cancel()
+
+	if err := s.rep.RehashPassword(ctx, rehash.userID, rehash.oldHash, rehash.newHash); err != nil {
+		log.Printf("rehash password for user %d: %v", rehash.userID, err)
+	}
+}
+
+// SignInOutput contains the authenticated user and generated access and refresh token metadata.
+// When the user has two-factor authentication enabled only ChallengeToken and ChallengeExpiresAt
+// are set, and the client must complete the second step to receive real tokens.
 type SignInOutput struct {
-	User      User
-	Token     string
-	ExpiresAt time.Time
+	User                  User
+	Token                 string
+	ExpiresAt             time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+	ChallengeToken        string
+	ChallengeExpiresAt    time.Time
 }
 
 // CustomClaims extends JWT registered claims with application-specific user identity fields.
 type CustomClaims struct {
-	UserID int64  `json:"uid"`
-	Email  string `json:"email"`
+	UserID    int64  `json:"uid"`
+	Email     string `json:"email"`
+	Role      Role   `json:"role"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -73,60 +245,1516 @@ type SignInInput struct {
 	Password string
 }
 
-// SignIn validates credentials and returns a signed short-lived JWT access token.
+// SignIn validates credentials and returns a short-lived JWT access token with a new refresh token family.
+// Repeated failures for the same account or IP are slowed down and eventually locked out.
 func (s *Service) SignIn(ctx context.Context, input SignInInput) (SignInOutput, error) {
-	// Fetch the active user by email for credential verification.
-	user, err := s.rep.GetByEmail(ctx, input.Email)
+	client := ClientInfoFromContext(ctx)
+
+	// Refuse throttled callers before hashing the password, which is the expensive part.
+	if err := s.checkSignInThrottle(ctx, input.Email, client.IP); err != nil {
+		return SignInOutput{}, err
+	}
+
+	// Fetch the user by email for credential verification, including accounts that are not active yet.
+	user, err := s.rep.GetAnyByEmail(ctx, input.Email)
 	if err != nil {
 		if errors.Is(err, ErrUserNotFound) {
+			if err := s.recordSignInFailure(ctx, input.Email, client.IP, 0, "unknown_email"); err != nil {
+				return SignInOutput{}, err
+			}
 			return SignInOutput{}, ErrInvalidCredentials
 		}
 		return SignInOutput{}, fmt.Errorf("service signin get user: %w", err)
 	}
 
 	// Compare the stored password hash with the provided raw password.
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)); err != nil {
+	if err := s.checkPassword(user, input.Password); err != nil {
+		if err := s.recordSignInFailure(ctx, input.Email, client.IP, user.ID, "invalid_password"); err != nil {
+			return SignInOutput{}, err
+		}
+		return SignInOutput{}, ErrInvalidCredentials
+	}
+
+	// Only explain why an account is blocked once the caller proved they know the password.
+	if user.EmailVerifiedAt.IsZero() {
+		s.recordEvent(ctx, user.ID, EventSignInFailure, map[string]any{"email": input.Email, "reason": "email_not_verified"})
+		return SignInOutput{}, ErrEmailNotVerified
+	}
+	if !user.IsActive {
+		s.recordEvent(ctx, user.ID, EventSignInFailure, map[string]any{"email": input.Email, "reason": "account_inactive"})
 		return SignInOutput{}, ErrInvalidCredentials
 	}
 
+	if err := s.clearAccountThrottle(ctx, user.Email); err != nil {
+		return SignInOutput{}, err
+	}
+
+	// Accounts with 2FA only get a short-lived challenge until the second factor is checked.
+	if !user.TOTPEnabledAt.IsZero() {
+		return s.issueTwoFactorChallenge(ctx, user, input.Password)
+	}
+
+	out, err := s.completeSignIn(ctx, user, "password")
+	if err != nil {
+		return SignInOutput{}, err
+	}
+	// The plain password is only available now, so this is the moment to upgrade old hashes.
+	if s.hasher.NeedsRehash(user.PasswordHash) {
+		go s.rehashPassword(ctx, user, input.Password)
+	}
+	return out, nil
+}
+
+// completeSignIn starts a session once every sign-in factor has been checked and records the
+// successful sign-in together with the method that completed it.
+func (s *Service) completeSignIn(ctx context.Context, user User, method string) (SignInOutput, error) {
+	out, err := s.startSession(ctx, user)
+	if err != nil {
+		return SignInOutput{}, err
+	}
+	s.recordEvent(ctx, user.ID, EventSignInSuccess, map[string]any{"method": method})
+	return out, nil
+}
+
+// startSession records a session for the signing-in device and issues the first access and
+// refresh token pair of its refresh token family.
+func (s *Service) startSession(ctx context.Context, user User) (SignInOutput, error) {
+	familyID, err := newFamilyID()
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("service start session family id: %w", err)
+	}
+
+	client := ClientInfoFromContext(ctx)
+	if _, err := s.rep.CreateSession(ctx, CreateSessionParams{
+		ID:        familyID,
+		UserID:    user.ID,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+	}); err != nil {
+		return SignInOutput{}, fmt.Errorf("service start session: %w", err)
+	}
+
+	return s.issueTokens(ctx, user, familyID)
+}
+
+// twoFactorChallengeTTL is how long the client has to submit the second factor after the password.
+const twoFactorChallengeTTL = 5 * time.Minute
+
+// issueTwoFactorChallenge signs a token proving the first sign-in step succeeded for user.
+// password is the one the caller signed in with, or empty for other first steps. An outdated
+// hash is upgraded from it in the background, but only stored once the second factor is checked.
+func (s *Service) issueTwoFactorChallenge(ctx context.Context, user User, password string) (SignInOutput, error) {
+	challengeID, err := newFamilyID()
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("service challenge id: %w", err)
+	}
+
+	now := time.Now()
+	exp := now.Add(twoFactorChallengeTTL)
+
+	token, err := s.signToken(jwt.RegisteredClaims{
+		Issuer:    tokenIssuer,
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Audience:  jwt.ClaimStrings{audienceTwoFactor},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(exp),
+		ID:        challengeID,
+	})
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("service sign challenge: %w", err)
+	}
+
+	if password != "" && s.hasher.NeedsRehash(user.PasswordHash) {
+		go s.prepareRehash(user, challengeID, password)
+	}
+
+	return SignInOutput{
+		User:               user,
+		ChallengeToken:     token,
+		ChallengeExpiresAt: exp,
+	}, nil
+}
+
+// SignInTwoFactor exchanges a challenge token and a TOTP or recovery code for real tokens.
+func (s *Service) SignInTwoFactor(ctx context.Context, challengeToken, code string) (SignInOutput, error) {
+	claims := jwt.RegisteredClaims{}
+	if err := s.parseToken(challengeToken, audienceTwoFactor, &claims); err != nil {
+		return SignInOutput{}, err
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return SignInOutput{}, ErrInvalidToken
+	}
+
+	user, err := s.rep.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return SignInOutput{}, ErrInvalidToken
+		}
+		return SignInOutput{}, fmt.Errorf("service signin 2fa get user: %w", err)
+	}
+
+	// Guessing codes counts against the same limits as guessing passwords.
+	client := ClientInfoFromContext(ctx)
+	if err := s.checkSignInThrottle(ctx, user.Email, client.IP); err != nil {
+		return SignInOutput{}, err
+	}
+
+	if err := s.verifySecondFactor(ctx, user, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactor) {
+			if err := s.recordSignInFailure(ctx, user.Email, client.IP, user.ID, "invalid_second_factor"); err != nil {
+				return SignInOutput{}, err
+			}
+		}
+		return SignInOutput{}, err
+	}
+
+	out, err := s.completeSignIn(ctx, user, "two_factor")
+	if err != nil {
+		return SignInOutput{}, err
+	}
+	if pending, ok := s.rehashes.Get(claims.ID); ok && pending.userID == user.ID {
+		s.rehashes.Delete(claims.ID)
+		go s.storeRehash(ctx, pending)
+	} else if s.hasher.NeedsRehash(user.PasswordHash) {
+		log.Printf("rehash password for user %d: no upgraded hash for this challenge on this replica", user.ID)
+	}
+	return out, nil
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery code.
+func (s *Service) verifySecondFactor(ctx context.Context, user User, code string) error {
+	if user.TOTPEnabledAt.IsZero() {
+		return ErrTwoFactorDisabled
+	}
+
+	if step, ok := validateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		fresh, err := s.rep.UseTOTPStep(ctx, user.ID, step)
+		if err != nil {
+			return fmt.Errorf("service verify totp: %w", err)
+		}
+		if !fresh {
+			return ErrInvalidTwoFactor
+		}
+		return nil
+	}
+
+	used, err := s.rep.UseRecoveryCode(ctx, user.ID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return fmt.Errorf("service verify recovery code: %w", err)
+	}
+	if !used {
+		return ErrInvalidTwoFactor
+	}
+	return nil
+}
+
+// TOTPEnrollment holds what an authenticator app needs to start generating codes.
+type TOTPEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// EnrollTOTP generates a pending TOTP secret for the user. Two-factor authentication stays off
+// until ConfirmTOTP receives a valid code, so an abandoned enrollment cannot lock anyone out.
+func (s *Service) EnrollTOTP(ctx context.Context, userID int64) (TOTPEnrollment, error) {
+	user, err := s.rep.GetByID(ctx, userID)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	if !user.TOTPEnabledAt.IsZero() {
+		return TOTPEnrollment{}, ErrTwoFactorEnabled
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("service enroll totp secret: %w", err)
+	}
+
+	if err := s.rep.SetTOTPSecret(ctx, user.ID, secret); err != nil {
+		return TOTPEnrollment{}, err
+	}
+
+	return TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totpURI(user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once code matches the pending secret and
+// returns freshly generated recovery codes. The plain codes are never stored or shown again.
+func (s *Service) ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error) {
+	user, err := s.rep.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabledAt.IsZero() {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorDisabled
+	}
+
+	step, ok := validateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactor
+	}
+
+	codes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("service confirm totp recovery codes: %w", err)
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, c := range codes {
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(c)))
+	}
+
+	if err := s.rep.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, fmt.Errorf("service confirm totp: %w", err)
+	}
+	if err := s.rep.EnableTOTP(ctx, user.ID, step); err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, user.ID, EventTwoFactorEnabled, nil)
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off after re-checking the password and a second factor.
+func (s *Service) DisableTOTP(ctx context.Context, userID int64, password, code string) error {
+	user, err := s.rep.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkPassword(user, password); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.verifySecondFactor(ctx, user, code); err != nil {
+		return err
+	}
+
+	if err := s.rep.DisableTOTP(ctx, user.ID); err != nil {
+		return fmt.Errorf("service disable totp: %w", err)
+	}
+	if err := s.rep.DeleteRecoveryCodes(ctx, user.ID); err != nil {
+		return fmt.Errorf("service disable totp: %w", err)
+	}
+
+	s.recordEvent(ctx, user.ID, EventTwoFactorDisabled, nil)
+	return nil
+}
+
+// Refresh rotates a refresh token and returns a fresh access token with its replacement.
+// Presenting a token that was already rotated revokes the whole family, because it means
+// either the client or an attacker holds a stale copy.
+func (s *Service) Refresh(ctx context.Context, rawToken string) (SignInOutput, error) {
+	token, err := s.rep.GetRefreshTokenByHash(ctx, hashToken(rawToken))
+	if err != nil {
+		return SignInOutput{}, err
+	}
+
+	if !token.RevokedAt.IsZero() {
+		return SignInOutput{}, ErrInvalidToken
+	}
+
+	if !token.RotatedAt.IsZero() {
+		return SignInOutput{}, s.revokeFamily(ctx, token.UserID, token.FamilyID)
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return SignInOutput{}, ErrInvalidToken
+	}
+
+	// Claim the token atomically so two concurrent refreshes cannot both succeed.
+	claimed, err := s.rep.RotateRefreshToken(ctx, token.ID)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("service refresh rotate: %w", err)
+	}
+	if !claimed {
+		return SignInOutput{}, s.revokeFamily(ctx, token.UserID, token.FamilyID)
+	}
+
+	user, err := s.rep.GetByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return SignInOutput{}, ErrInvalidToken
+		}
+		return SignInOutput{}, fmt.Errorf("service refresh get user: %w", err)
+	}
+
+	if err := s.rep.TouchSession(ctx, token.FamilyID, ClientInfoFromContext(ctx)); err != nil {
+		return SignInOutput{}, fmt.Errorf("service refresh: %w", err)
+	}
+
+	out, err := s.issueTokens(ctx, user, token.FamilyID)
+	if err != nil {
+		return SignInOutput{}, err
+	}
+	s.recordEvent(ctx, user.ID, EventTokenRefresh, map[string]any{"session_id": token.FamilyID})
+	return out, nil
+}
+
+// revokeFamily revokes a refresh token family and its session after reuse and reports the reuse to the caller.
+func (s *Service) revokeFamily(ctx context.Context, userID int64, familyID string) error {
+	if err := s.rep.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+		return fmt.Errorf("service revoke refresh family: %w", err)
+	}
+	if err := s.rep.RevokeSession(ctx, userID, familyID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return fmt.Errorf("service revoke refresh family: %w", err)
+	}
+	s.sessions.Set(familyID, false)
+	s.recordEvent(ctx, userID, EventRefreshTokenReuse, map[string]any{"session_id": familyID})
+	return ErrRefreshTokenReused
+}
+
+// issueTokens signs an access token for the user and stores a new refresh token in the given family.
+func (s *Service) issueTokens(ctx context.Context, user User, familyID string) (SignInOutput, error) {
 	// Define token issuance and expiration timestamps.
 	now := time.Now()
-	exp := now.Add(15 * time.Minute)
+	exp := now.Add(accessTokenTTL)
 
 	// Build application and standard JWT claims for this session.
 	claims := CustomClaims{
-		UserID: user.ID,
-		Email:  user.Email,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		SessionID: familyID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "devlog-auth-service",
+			Issuer:    tokenIssuer,
 			Subject:   strconv.FormatInt(user.ID, 10),
+			Audience:  jwt.ClaimStrings{audienceAccess},
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(exp),
 		},
 	}
 
-	// Read signing secret from environment and fail fast when missing.
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" || len([]rune(secret)) < 32 {
-		return SignInOutput{}, ErrJWTSecretNotSet
+	// Sign the access token with the configured key.
+	signedTokenString, err := s.signToken(claims)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("service sign token: %w", err)
 	}
 
-	// Create and sign the JWT token with HS256.
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedTokenString, err := token.SignedString([]byte(secret))
+	// Generate the opaque refresh token and persist only its hash.
+	rawRefresh, refreshHash, err := newOpaqueToken()
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("service generate refresh token: %w", err)
+	}
 
+	refresh, err := s.rep.CreateRefreshToken(ctx, CreateRefreshTokenParams{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: refreshHash,
+		ExpiresAt: now.Add(refreshTokenTTL),
+	})
 	if err != nil {
-		return SignInOutput{}, fmt.Errorf("service signin sign token: %w", err)
+		return SignInOutput{}, fmt.Errorf("service store refresh token: %w", err)
 	}
 
 	// Return authenticated user metadata together with token payload.
 	return SignInOutput{
-		User:      user,
-		Token:     signedTokenString,
-		ExpiresAt: exp,
+		User:                  user,
+		Token:                 signedTokenString,
+		ExpiresAt:             exp,
+		RefreshToken:          rawRefresh,
+		RefreshTokenExpiresAt: refresh.ExpiresAt,
 	}, nil
 }
 
+// tokenCutoffLeeway is how far before token_invalid_before a token may have been issued and still
+// pass CheckTokenIssuedAt. It absorbs the whole-second iat claim and clock drift between replicas,
+// so a token issued right after the cutoff is never refused. Tokens issued within the leeway before
+// the cutoff are still refused by CheckSession, because moving the cutoff revokes every session.
+const tokenCutoffLeeway = 5 * time.Second
+
+// CheckTokenIssuedAt rejects access tokens issued before the user's token_invalid_before cutoff,
+// less tokenCutoffLeeway. Inactive or deleted users are rejected as well.
+func (s *Service) CheckTokenIssuedAt(ctx context.Context, userID int64, issuedAt time.Time) error {
+	cutoff, ok := s.tokenCutoffs.Get(userID)
+	if !ok {
+		var err error
+		cutoff, err = s.rep.GetTokenInvalidBefore(ctx, userID)
+		if err != nil {
+			if errors.Is(err, ErrUserNotFound) {
+				return ErrTokenRevoked
+			}
+			return fmt.Errorf("service check token: %w", err)
+		}
+		s.tokenCutoffs.Set(userID, cutoff)
+	}
+
+	if issuedAt.Before(cutoff.Add(-tokenCutoffLeeway)) {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// CheckSession rejects access tokens whose session was revoked. Revocations made through this
+// process take effect immediately; other replicas notice once their cached entry expires.
+func (s *Service) CheckSession(ctx context.Context, userID int64, sessionID string) error {
+	active, ok := s.sessions.Get(sessionID)
+	if !ok {
+		session, err := s.rep.GetSession(ctx, sessionID)
+		if err != nil {
+			if errors.Is(err, ErrSessionNotFound) {
+				return ErrTokenRevoked
+			}
+			return fmt.Errorf("service check session: %w", err)
+		}
+		active = session.UserID == userID && session.RevokedAt.IsZero()
+
+		// Refreshing last_seen_at only on a cache miss keeps it within a TTL of the truth
+		// without a write per request.
+		if active {
+			if err := s.rep.TouchSession(ctx, sessionID, ClientInfoFromContext(ctx)); err != nil {
+				return fmt.Errorf("service check session: %w", err)
+			}
+		}
+		s.sessions.Set(sessionID, active)
+	}
+
+	if !active {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// ListSessions returns the devices the user is currently signed in on.
+func (s *Service) ListSessions(ctx context.Context, userID int64) ([]Session, error) {
+	sessions, err := s.rep.ListUserSessions(ctx, userID, time.Now().Add(-refreshTokenTTL))
+	if err != nil {
+		return nil, fmt.Errorf("service list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs the user out of a single device: its refresh tokens stop working at once
+// and its access tokens are rejected by AuthMiddleware.
+func (s *Service) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	return s.revokeSession(ctx, userID, sessionID)
+}
+
+// revokeSession revokes a session owned by userID together with its refresh token family.
+func (s *Service) revokeSession(ctx context.Context, userID int64, sessionID string) error {
+	if err := s.rep.RevokeSession(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return err
+		}
+		return fmt.Errorf("service revoke session: %w", err)
+	}
+	s.sessions.Set(sessionID, false)
+
+	if err := s.rep.RevokeRefreshTokenFamily(ctx, sessionID); err != nil {
+		return fmt.Errorf("service revoke session: %w", err)
+	}
+	return nil
+}
+
+// LogoutAll signs the user out of every device by invalidating all issued tokens.
+func (s *Service) LogoutAll(ctx context.Context, userID int64) error {
+	return s.invalidateTokens(ctx, userID)
+}
+
+// invalidateTokens bumps token_invalid_before and revokes all refresh tokens and sessions for the user.
+// Every flow that changes credentials or deactivates an account must go through it.
+func (s *Service) invalidateTokens(ctx context.Context, userID int64) error {
+	// The cutoff comes from the clock that stamps iat, not from the database, so tokens issued
+	// right after it, like the new session ChangePassword starts, are never mistaken for older ones.
+	cutoff := time.Now()
+	if err := s.rep.InvalidateUserTokens(ctx, userID, cutoff); err != nil {
+		return fmt.Errorf("service invalidate tokens: %w", err)
+	}
+	s.tokenCutoffs.Set(userID, cutoff)
+
+	if err := s.rep.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("service invalidate tokens: %w", err)
+	}
+	sessionIDs, err := s.rep.RevokeUserSessions(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("service invalidate tokens: %w", err)
+	}
+	// Tokens issued within tokenCutoffLeeway before the cutoff are only caught by their session.
+	for _, id := range sessionIDs {
+		s.sessions.Set(id, false)
+	}
+	return nil
+}
+
+// Email verification settings.
+const (
+	verificationTokenTTL  = 24 * time.Hour
+	verificationResendGap = time.Minute
+)
+
+// verificationClaims identify the account and address an email verification link was sent for.
+type verificationClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// sendVerificationEmail emails a signed link that activates the account when opened.
+func (s *Service) sendVerificationEmail(ctx context.Context, user User) error {
+	now := time.Now()
+	token, err := s.signToken(verificationClaims{
+		Email: user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   strconv.FormatInt(user.ID, 10),
+			Audience:  jwt.ClaimStrings{audienceEmailVerification},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(verificationTokenTTL)),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("sign verification token: %w", err)
+	}
+
+	link := s.appURL + "/api/v1/users/verify?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your devlog email",
+		Body: "Welcome to devlog!\n\n" +
+			"Open this link within 24 hours to verify your email and activate your account:\n" + link,
+	})
+}
+
+// VerifyEmail activates the account named by a signed verification token.
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	claims := verificationClaims{}
+	if err := s.parseToken(token, audienceEmailVerification, &claims); err != nil {
+		return err
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	return s.rep.VerifyEmail(ctx, userID, claims.Email)
+}
+
+// ResendVerification emails a new verification link to an unverified account, at most once per
+// verificationResendGap. Unknown and already verified addresses, and requests inside the gap, are
+// all ignored alike so the endpoint cannot be used to probe accounts.
+func (s *Service) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.rep.GetAnyByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return fmt.Errorf("service resend verification get user: %w", err)
+	}
+	if !user.EmailVerifiedAt.IsZero() {
+		return nil
+	}
+
+	// Claim the send slot atomically so concurrent requests cannot bypass the limit. Telling the
+	// caller to retry later would confirm the account exists, so a request inside the gap is
+	// dropped silently.
+	claimed, err := s.rep.MarkVerificationSent(ctx, user.ID, time.Now().Add(-verificationResendGap))
+	if err != nil {
+		return fmt.Errorf("service resend verification: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		// Free the slot again so the user can retry instead of waiting out the gap for an
+		// email that never left.
+		if err := s.rep.ReleaseVerificationSent(context.WithoutCancel(ctx), user.ID); err != nil {
+			log.Printf("service resend verification release: %v", err)
+		}
+		return fmt.Errorf("service resend verification send: %w", err)
+	}
+	return nil
+}
+
+// passwordResetTTL is how long an emailed password reset link stays usable.
+const passwordResetTTL = 30 * time.Minute
+
+// ForgotPassword emails a single-use reset link when an active account exists for email.
+// It returns nil for unknown addresses so callers cannot probe which emails are registered.
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.rep.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return fmt.Errorf("service forgot password get user: %w", err)
+	}
+
+	rawToken, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("service forgot password token: %w", err)
+	}
+
+	if err := s.rep.CreatePasswordResetToken(ctx, user.ID, tokenHash, time.Now().Add(passwordResetTTL)); err != nil {
+		return fmt.Errorf("service forgot password: %w", err)
+	}
+
+	link := s.appURL + "/reset-password?token=" + url.QueryEscape(rawToken)
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your devlog password",
+		Body: "Someone asked to reset the password for your devlog account.\n\n" +
+			"Open this link within 30 minutes to choose a new password:\n" + link + "\n\n" +
+			"If you did not ask for this, you can ignore this email.",
+	})
+	if err != nil {
+		return fmt.Errorf("service forgot password send: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword consumes a reset token, stores the new password, and signs the user out everywhere.
+func (s *Service) ResetPassword(ctx context.Context, rawToken, newPassword string) error {
+	// Screen before consuming the token so a rejected password does not burn the link.
+	if err := s.screenPassword(ctx, newPassword); err != nil {
+		return err
+	}
+
+	userID, err := s.rep.ConsumePasswordResetToken(ctx, hashToken(rawToken))
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("service reset password hash: %w", err)
+	}
+
+	if err := s.rep.UpdatePasswordHash(ctx, userID, passwordHash); err != nil {
+		return fmt.Errorf("service reset password: %w", err)
+	}
+
+	// Any other reset link that is still in someone's inbox must stop working as well.
+	if err := s.rep.DiscardPasswordResetTokens(ctx, userID); err != nil {
+		return fmt.Errorf("service reset password: %w", err)
+	}
+	if err := s.invalidateTokens(ctx, userID); err != nil {
+		return err
+	}
+
+	s.recordEvent(ctx, userID, EventPasswordReset, nil)
+	return nil
+}
+
+const (
+	// magicLinkTTL is how long an emailed sign-in link stays usable.
+	magicLinkTTL = 5 * time.Minute
+	// magicLinkResendInterval is the minimum gap between two sign-in links for one account.
+	magicLinkResendInterval = time.Minute
+)
+
+// RequestMagicLink emails a single-use sign-in link when an active account exists for email.
+// The link only works from the IP and user agent that requested it. Like ForgotPassword it
+// returns nil for unknown addresses, and it quietly skips accounts that were sent a link recently.
+func (s *Service) RequestMagicLink(ctx context.Context, email string) error {
+	user, err := s.rep.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return fmt.Errorf("service request magic link get user: %w", err)
+	}
+	if user.EmailVerifiedAt.IsZero() {
+		return nil
+	}
+
+	recent, err := s.rep.CountRecentMagicLinkTokens(ctx, user.ID, time.Now().Add(-magicLinkResendInterval))
+	if err != nil {
+		return fmt.Errorf("service request magic link: %w", err)
+	}
+	if recent > 0 {
+		return nil
+	}
+
+	rawToken, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("service request magic link token: %w", err)
+	}
+
+	client := ClientInfoFromContext(ctx)
+	if err := s.rep.CreateMagicLinkToken(ctx, user.ID, tokenHash, clientHash(client), time.Now().Add(magicLinkTTL)); err != nil {
+		return fmt.Errorf("service request magic link: %w", err)
+	}
+
+	link := s.appURL + "/api/v1/users/signin/magic/callback?token=" + url.QueryEscape(rawToken)
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your devlog sign-in link",
+		Body: "Open this link within 5 minutes to sign in to devlog:\n" + link + "\n\n" +
+			"The link works once, and only in the browser you requested it from.\n" +
+			"If you did not ask for this, you can ignore this email.",
+	})
+	if err != nil {
+		return fmt.Errorf("service request magic link send: %w", err)
+	}
+	return nil
+}
+
+// SignInMagicLink exchanges an emailed sign-in link for the same result as a password sign-in.
+// Links opened a second time or from another client are rejected and written to the audit log,
+// and accounts or IPs locked out by failed sign-ins are refused like in SignIn.
+func (s *Service) SignInMagicLink(ctx context.Context, rawToken string) (SignInOutput, error) {
+	token, err := s.rep.GetMagicLinkTokenByHash(ctx, hashToken(rawToken))
+	if err != nil {
+		return SignInOutput{}, err
+	}
+
+	if !token.UsedAt.IsZero() {
+		s.recordEvent(ctx, token.UserID, EventMagicLinkReplay, map[string]any{"token_id": token.ID})
+		return SignInOutput{}, ErrInvalidToken
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return SignInOutput{}, ErrInvalidToken
+	}
+
+	// A mismatch does not burn the link, so a scanner opening it first cannot lock the user out.
+	if clientHash(ClientInfoFromContext(ctx)) != token.ClientHash {
+		s.recordEvent(ctx, token.UserID, EventMagicLinkClientMismatch, map[string]any{"token_id": token.ID})
+		return SignInOutput{}, ErrInvalidToken
+	}
+
+	user, err := s.rep.GetByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return SignInOutput{}, ErrInvalidToken
+		}
+		return SignInOutput{}, fmt.Errorf("service magic link signin get user: %w", err)
+	}
+
+	// A locked account or IP stays locked whichever way the caller signs in. The check comes
+	// before the link is used, so the link still works once the lock runs out.
+	if err := s.checkSignInThrottle(ctx, user.Email, ClientInfoFromContext(ctx).IP); err != nil {
+		return SignInOutput{}, err
+	}
+
+	claimed, err := s.rep.UseMagicLinkToken(ctx, token.ID)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("service magic link signin: %w", err)
+	}
+	if !claimed {
+		// Another request used the link between the lookup and the update.
+		s.recordEvent(ctx, token.UserID, EventMagicLinkReplay, map[string]any{"token_id": token.ID})
+		return SignInOutput{}, ErrInvalidToken
+	}
+
+	if err := s.clearAccountThrottle(ctx, user.Email); err != nil {
+		return SignInOutput{}, err
+	}
+
+	// The link proves control of the inbox, which replaces the password but not a second factor.
+	if !user.TOTPEnabledAt.IsZero() {
+		return s.issueTwoFactorChallenge(ctx, user, "")
+	}
+
+	return s.completeSignIn(ctx, user, "magic_link")
+}
+
+// UpdateProfileInput lists the profile fields a user may change. Nil fields are left untouched;
+// an empty SocialLinks map removes every link.
+type UpdateProfileInput struct {
+	Username    *string
+	DisplayName *string
+	Bio         *string
+	Website     *string
+	SocialLinks map[string]string
+}
+
+// UpdateProfile applies the given changes to the user's profile and returns the updated user.
+func (s *Service) UpdateProfile(ctx context.Context, userID int64, input UpdateProfileInput) (User, error) {
+	if input.Bio != nil {
+		bio := strings.TrimSpace(*input.Bio)
+		input.Bio = &bio
+	}
+
+	if err := s.rep.UpdateProfile(ctx, userID, UpdateProfileParams(input)); err != nil {
+		if errors.Is(err, ErrUsernameTaken) || errors.Is(err, ErrConflict) || errors.Is(err, ErrUserNotFound) {
+			return User{}, err
+		}
+		return User{}, fmt.Errorf("service update profile: %w", err)
+	}
+
+	return s.rep.GetByID(ctx, userID)
+}
+
+// GetPublicProfile returns the public author profile for username. An author who blocked viewerID
+// is reported as not found; viewerID is zero for anonymous readers.
+func (s *Service) GetPublicProfile(ctx context.Context, viewerID int64, username string) (PublicProfile, error) {
+	return s.rep.GetPublicProfile(ctx, username, viewerID)
+}
+
+// FollowUser makes followerID follow the active user with the given username. Either user blocking
+// the other rules the follow out.
+func (s *Service) FollowUser(ctx context.Context, followerID int64, username string) error {
+	followeeID, err := s.rep.GetIDByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if followeeID == followerID {
+		return ErrCannotFollowSelf
+	}
+
+	blocked, err := s.rep.IsBlockedBetween(ctx, followerID, followeeID)
+	if err != nil {
+		return fmt.Errorf("service follow user: %w", err)
+	}
+	if blocked {
+		return ErrUserBlocked
+	}
+	return s.rep.FollowUser(ctx, followerID, followeeID)
+}
+
+// UnfollowUser stops followerID following the user with the given username.
+func (s *Service) UnfollowUser(ctx context.Context, followerID int64, username string) error {
+	followeeID, err := s.rep.GetIDByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.rep.UnfollowUser(ctx, followerID, followeeID)
+}
+
+// ListFollowers returns a page of the user's followers together with their total number, as seen by
+// viewerID. Blocks hide the list like they hide the profile, and followers who blocked the viewer
+// are left out.
+func (s *Service) ListFollowers(ctx context.Context, viewerID int64, username string, input ListUsersInput) ([]FollowEntry, int64, error) {
+	input = NormalizeListUsersInput(input)
+
+	userID, err := s.rep.GetVisibleIDByUsername(ctx, username, viewerID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries, err := s.rep.ListFollowers(ctx, userID, viewerID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service list followers: %w", err)
+	}
+	total, err := s.rep.CountFollowers(ctx, userID, viewerID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service list followers: %w", err)
+	}
+	return entries, total, nil
+}
+
+// ListFollowing returns a page of the accounts the user follows together with their total number,
+// as seen by viewerID, with the same block rules as ListFollowers.
+func (s *Service) ListFollowing(ctx context.Context, viewerID int64, username string, input ListUsersInput) ([]FollowEntry, int64, error) {
+	input = NormalizeListUsersInput(input)
+
+	userID, err := s.rep.GetVisibleIDByUsername(ctx, username, viewerID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries, err := s.rep.ListFollowing(ctx, userID, viewerID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service list following: %w", err)
+	}
+	total, err := s.rep.CountFollowing(ctx, userID, viewerID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service list following: %w", err)
+	}
+	return entries, total, nil
+}
+
+// BlockUser makes blockerID block the active user with the given username. The blocked user stops
+// seeing the blocker's posts, and any follow between the two is dropped.
+func (s *Service) BlockUser(ctx context.Context, blockerID int64, username string) error {
+	blockedID, err := s.rep.GetIDByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if blockedID == blockerID {
+		return ErrCannotBlockSelf
+	}
+	return s.rep.BlockUser(ctx, blockerID, blockedID)
+}
+
+// UnblockUser lifts blockerID's block on the user with the given username.
+func (s *Service) UnblockUser(ctx context.Context, blockerID int64, username string) error {
+	// The blocked account may have been deactivated since, which must not keep the block in place.
+	blockedID, err := s.rep.GetAnyIDByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.rep.UnblockUser(ctx, blockerID, blockedID)
+}
+
+// ListBlockedUsers returns a page of the accounts the user has blocked.
+func (s *Service) ListBlockedUsers(ctx context.Context, userID int64, input ListUsersInput) ([]RelationEntry, error) {
+	input = NormalizeListUsersInput(input)
+
+	entries, err := s.rep.ListBlockedUsers(ctx, userID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("service list blocked users: %w", err)
+	}
+	return entries, nil
+}
+
+// MuteUser hides the posts of the active user with the given username from muterID's post lists
+// and feed. Unlike a block, the muted user is not told anything and can still interact.
+func (s *Service) MuteUser(ctx context.Context, muterID int64, username string) error {
+	mutedID, err := s.rep.GetIDByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if mutedID == muterID {
+		return ErrCannotMuteSelf
+	}
+	return s.rep.MuteUser(ctx, muterID, mutedID)
+}
+
+// UnmuteUser lifts muterID's mute on the user with the given username.
+func (s *Service) UnmuteUser(ctx context.Context, muterID int64, username string) error {
+	// The muted account may have been deactivated since, which must not keep the mute in place.
+	mutedID, err := s.rep.GetAnyIDByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.rep.UnmuteUser(ctx, muterID, mutedID)
+}
+
+// ListMutedUsers returns a page of the accounts the user has muted.
+func (s *Service) ListMutedUsers(ctx context.Context, userID int64, input ListUsersInput) ([]RelationEntry, error) {
+	input = NormalizeListUsersInput(input)
+
+	entries, err := s.rep.ListMutedUsers(ctx, userID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("service list muted users: %w", err)
+	}
+	return entries, nil
+}
+
+// ChangePassword replaces the password after checking the current one. Every existing token is
+// invalidated, and a new session is started so the caller stays signed in on this device.
+func (s *Service) ChangePassword(ctx context.Context, userID int64, currentPassword, newPassword string) (SignInOutput, error) {
+	user, err := s.rep.GetByID(ctx, userID)
+	if err != nil {
+		return SignInOutput{}, err
+	}
+
+	if err := s.checkPassword(user, currentPassword); err != nil {
+		return SignInOutput{}, ErrInvalidCredentials
+	}
+
+	if err := s.screenPassword(ctx, newPassword, user.Email, user.Username); err != nil {
+		return SignInOutput{}, err
+	}
+
+	passwordHash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return SignInOutput{}, fmt.Errorf("service change password hash: %w", err)
+	}
+
+	if err := s.rep.UpdatePasswordHash(ctx, user.ID, passwordHash); err != nil {
+		return SignInOutput{}, fmt.Errorf("service change password: %w", err)
+	}
+	if err := s.rep.DiscardPasswordResetTokens(ctx, user.ID); err != nil {
+		return SignInOutput{}, fmt.Errorf("service change password: %w", err)
+	}
+	if err := s.invalidateTokens(ctx, user.ID); err != nil {
+		return SignInOutput{}, err
+	}
+
+	s.recordEvent(ctx, user.ID, EventPasswordChanged, nil)
+	return s.startSession(ctx, user)
+}
+
+// emailChangeClaims carry a pending email change. Email pins the address the change was requested
+// from, so a stale link cannot undo a later change.
+type emailChangeClaims struct {
+	Email    string `json:"email"`
+	NewEmail string `json:"new_email"`
+	jwt.RegisteredClaims
+}
+
+// RequestEmailChange sends a confirmation link to newEmail. The address only changes once the
+// link is opened, which proves the user controls the new mailbox.
+func (s *Service) RequestEmailChange(ctx context.Context, userID int64, password, newEmail string) error {
+	user, err := s.rep.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkPassword(user, password); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	// Fail early on taken addresses; UpdateEmail still catches races through the unique index.
+	if _, err := s.rep.GetAnyByEmail(ctx, newEmail); err == nil {
+		return ErrEmailTaken
+	} else if !errors.Is(err, ErrUserNotFound) {
+		return fmt.Errorf("service request email change: %w", err)
+	}
+
+	now := time.Now()
+	token, err := s.signToken(emailChangeClaims{
+		Email:    user.Email,
+		NewEmail: newEmail,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   strconv.FormatInt(user.ID, 10),
+			Audience:  jwt.ClaimStrings{audienceEmailChange},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(verificationTokenTTL)),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("service request email change sign: %w", err)
+	}
+
+	link := s.appURL + "/api/v1/users/email/confirm?token=" + url.QueryEscape(token)
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      newEmail,
+		Subject: "Confirm your new devlog email",
+		Body: "Someone asked to use this address for the devlog account " + user.Username + ".\n\n" +
+			"Open this link within 24 hours to confirm the change:\n" + link + "\n\n" +
+			"If you did not ask for this, you can ignore this email.",
+	})
+	if err != nil {
+		return fmt.Errorf("service request email change send: %w", err)
+	}
+	return nil
+}
+
+// ConfirmEmailChange applies the email change named by a signed confirmation token, signs the
+// user out everywhere, and lets the previous address know about the change.
+func (s *Service) ConfirmEmailChange(ctx context.Context, token string) error {
+	claims := emailChangeClaims{}
+	if err := s.parseToken(token, audienceEmailChange, &claims); err != nil {
+		return err
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	if err := s.rep.UpdateEmail(ctx, userID, claims.Email, claims.NewEmail); err != nil {
+		return err
+	}
+	if err := s.invalidateTokens(ctx, userID); err != nil {
+		return err
+	}
+
+	// The change already happened, so a failed notice is logged rather than reported.
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      claims.Email,
+		Subject: "Your devlog email was changed",
+		Body: "The email address of your devlog account was changed to " + claims.NewEmail + ".\n\n" +
+			"If you did not make this change, reset your password and contact support right away.",
+	})
+	if err != nil {
+		log.Printf("email change notice for user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// ExportAccount gathers everything stored about the user for a personal data export.
+func (s *Service) ExportAccount(ctx context.Context, userID int64) (AccountExport, error) {
+	user, err := s.rep.GetByID(ctx, userID)
+	if err != nil {
+		return AccountExport{}, err
+	}
+
+	posts, err := s.rep.ListAuthoredPosts(ctx, userID)
+	if err != nil {
+		return AccountExport{}, fmt.Errorf("service export account: %w", err)
+	}
+	events, err := s.rep.ListUserAuthEvents(ctx, userID)
+	if err != nil {
+		return AccountExport{}, fmt.Errorf("service export account: %w", err)
+	}
+	// Revoked sessions are gone for good; every other session is still on record.
+	sessions, err := s.rep.ListUserSessions(ctx, userID, time.Time{})
+	if err != nil {
+		return AccountExport{}, fmt.Errorf("service export account: %w", err)
+	}
+	following, err := s.rep.ListFollowing(ctx, userID, userID, math.MaxInt32, 0)
+	if err != nil {
+		return AccountExport{}, fmt.Errorf("service export account: %w", err)
+	}
+	followers, err := s.rep.ListFollowers(ctx, userID, userID, math.MaxInt32, 0)
+	if err != nil {
+		return AccountExport{}, fmt.Errorf("service export account: %w", err)
+	}
+
+	return AccountExport{
+		User:       user,
+		Posts:      posts,
+		Events:     events,
+		Sessions:   sessions,
+		Following:  following,
+		Followers:  followers,
+		ExportedAt: time.Now(),
+	}, nil
+}
+
+// defaultDeletionGracePeriod is how long a deleted account can be restored unless configured otherwise.
+const defaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+// DeleteAccount deactivates the user's account right away and schedules it to be purged once the
+// grace period is over. The user is signed out everywhere and emailed a link that restores the
+// account until then. It returns when the account will be purged.
+func (s *Service) DeleteAccount(ctx context.Context, userID int64, password string) (time.Time, error) {
+	user, err := s.rep.GetByID(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if err := s.checkPassword(user, password); err != nil {
+		return time.Time{}, ErrInvalidCredentials
+	}
+
+	// The restore link carries a nonce so it only restores this deletion, not a later one.
+	nonce, nonceHash, err := newOpaqueToken()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("service delete account: %w", err)
+	}
+
+	now := time.Now()
+	purgeAfter := now.Add(s.deletionGrace)
+	if err := s.rep.ScheduleAccountDeletion(ctx, user.ID, purgeAfter, nonceHash); err != nil {
+		return time.Time{}, err
+	}
+	if err := s.invalidateTokens(ctx, user.ID); err != nil {
+		return time.Time{}, err
+	}
+	// Forget the cached cutoff so the next request sees the account as inactive.
+	s.tokenCutoffs.Delete(user.ID)
+
+	s.recordEvent(ctx, user.ID, EventDeletionRequested, map[string]any{"purge_after": purgeAfter})
+
+	token, err := s.signToken(jwt.RegisteredClaims{
+		Issuer:    tokenIssuer,
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Audience:  jwt.ClaimStrings{audienceAccountRestore},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(purgeAfter),
+		ID:        nonce,
+	})
+	if err != nil {
+		log.Printf("service delete account sign restore token: %v", err)
+		return purgeAfter, nil
+	}
+
+	// The account is already scheduled, so a failed notice is logged rather than returned.
+	link := s.appURL + "/api/v1/users/deletion/cancel?token=" + url.QueryEscape(token)
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your devlog account is scheduled for deletion",
+		Body: "Your devlog account " + user.Username + " has been deactivated and will be deleted for good on " +
+			purgeAfter.UTC().Format("2 January 2006") + ", together with all of its posts.\n\n" +
+			"Changed your mind? Open this link before then to restore the account:\n" + link,
+	})
+	if err != nil {
+		log.Printf("service delete account send notice: %v", err)
+	}
+	return purgeAfter, nil
+}
+
+// CancelAccountDeletion restores an account from the link emailed by DeleteAccount, as long as
+// the grace period is not over yet and the link was sent for the pending deletion.
+func (s *Service) CancelAccountDeletion(ctx context.Context, token string) error {
+	claims := jwt.RegisteredClaims{}
+	if err := s.parseToken(token, audienceAccountRestore, &claims); err != nil {
+		return err
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	// Links signed before restore links carried a nonce have no jti.
+	var nonceHash *string
+	if claims.ID != "" {
+		hash := hashToken(claims.ID)
+		nonceHash = &hash
+	}
+
+	if err := s.rep.CancelAccountDeletion(ctx, userID, nonceHash); err != nil {
+		return err
+	}
+	s.tokenCutoffs.Delete(userID)
+
+	s.recordEvent(ctx, userID, EventDeletionCancelled, nil)
+	return nil
+}
+
+// PurgeDeletedAccounts hard-deletes the accounts whose deletion grace period is over.
+func (s *Service) PurgeDeletedAccounts(ctx context.Context) (int64, error) {
+	return s.rep.PurgeDeletedAccounts(ctx)
+}
+
+// RunDeletionPurger purges expired account deletions every interval until ctx is cancelled.
+// Running it on several replicas at once is safe; each account is only deleted once.
+func (s *Service) RunDeletionPurger(ctx context.Context, interval time.Duration) {
+	periodic.Run(ctx, interval, func(ctx context.Context) {
+		n, err := s.PurgeDeletedAccounts(ctx)
+		switch {
+		case err != nil:
+			log.Printf("purge deleted accounts: %v", err)
+		case n > 0:
+			log.Printf("purged %d deleted accounts", n)
+		}
+	})
+}
+
+// CreatePersonalAccessTokenInput defines the fields required to create a personal access token.
+type CreatePersonalAccessTokenInput struct {
+	UserID    int64
+	Name      string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// CreatePersonalAccessToken creates a scoped token and returns it together with the plain value,
+// which is shown to the user once and never stored.
+func (s *Service) CreatePersonalAccessToken(ctx context.Context, input CreatePersonalAccessTokenInput) (PersonalAccessToken, string, error) {
+	if !input.ExpiresAt.IsZero() && !input.ExpiresAt.After(time.Now()) {
+		return PersonalAccessToken{}, "", ErrInvalidExpiry
+	}
+
+	raw, prefix, tokenHash, err := newPersonalAccessToken()
+	if err != nil {
+		return PersonalAccessToken{}, "", fmt.Errorf("service create personal access token: %w", err)
+	}
+
+	token, err := s.rep.CreatePersonalAccessToken(ctx, CreatePersonalAccessTokenParams{
+		UserID:    input.UserID,
+		Name:      input.Name,
+		Prefix:    prefix,
+		TokenHash: tokenHash,
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		return PersonalAccessToken{}, "", fmt.Errorf("service create personal access token: %w", err)
+	}
+	return token, raw, nil
+}
+
+// ListPersonalAccessTokens returns the user's active personal access tokens.
+func (s *Service) ListPersonalAccessTokens(ctx context.Context, userID int64) ([]PersonalAccessToken, error) {
+	tokens, err := s.rep.ListPersonalAccessTokens(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service list personal access tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokePersonalAccessToken revokes one of the user's personal access tokens.
+func (s *Service) RevokePersonalAccessToken(ctx context.Context, userID, tokenID int64) error {
+	return s.rep.RevokePersonalAccessToken(ctx, userID, tokenID)
+}
+
+// CreateInviteInput defines an invite to create. An empty Email lets any address use the invite
+// and a zero ExpiresAt means it never expires.
+type CreateInviteInput struct {
+	CreatedBy int64
+	Email     string
+	MaxUses   int32
+	ExpiresAt time.Time
+}
+
+// CreateInvite creates an invite code and returns it together with the plain code, which is shown
+// to its creator once and never stored.
+func (s *Service) CreateInvite(ctx context.Context, input CreateInviteInput) (Invite, string, error) {
+	if !input.ExpiresAt.IsZero() && !input.ExpiresAt.After(time.Now()) {
+		return Invite{}, "", ErrInvalidExpiry
+	}
+
+	raw, prefix, codeHash, err := newInviteCode()
+	if err != nil {
+		return Invite{}, "", fmt.Errorf("service create invite: %w", err)
+	}
+
+	invite, err := s.rep.CreateInvite(ctx, CreateInviteParams{
+		CreatedBy: input.CreatedBy,
+		Prefix:    prefix,
+		CodeHash:  codeHash,
+		Email:     input.Email,
+		MaxUses:   input.MaxUses,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		return Invite{}, "", fmt.Errorf("service create invite: %w", err)
+	}
+	return invite, raw, nil
+}
+
+// ListInvites returns the invites the user created that have not been revoked.
+func (s *Service) ListInvites(ctx context.Context, userID int64) ([]Invite, error) {
+	invites, err := s.rep.ListInvitesByCreator(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service list invites: %w", err)
+	}
+	return invites, nil
+}
+
+// RevokeInvite revokes one of the user's invites so it can no longer be used.
+func (s *Service) RevokeInvite(ctx context.Context, userID, inviteID int64) error {
+	return s.rep.RevokeInvite(ctx, userID, inviteID)
+}
+
+// AuthenticatePersonalAccessToken resolves a presented personal access token to its owner and scopes.
+func (s *Service) AuthenticatePersonalAccessToken(ctx context.Context, raw string) (AuthUser, error) {
+	owner, err := s.rep.GetActivePersonalAccessToken(ctx, hashToken(raw))
+	if err != nil {
+		return AuthUser{}, err
+	}
+	token := owner.Token
+
+	if err := s.rep.TouchPersonalAccessToken(ctx, token.ID); err != nil {
+		return AuthUser{}, fmt.Errorf("service authenticate personal access token: %w", err)
+	}
+
+	// An empty scope list must still restrict the token, so never hand back nil here.
+	scopes := token.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return AuthUser{
+		ID:     token.UserID,
+		Email:  owner.Email,
+		Role:   owner.Role,
+		Scopes: scopes,
+	}, nil
+}
+
+// ListUsersInput defines pagination parameters for the admin user listing.
+type ListUsersInput struct {
+	Limit  int32
+	Offset int32
+}
+
+const (
+	defaultUserPageLimit = 20
+	maxUserPageLimit     = 100
+)
+
+// NormalizeListUsersInput clamps limit to valid bounds and ensures offset is non-negative.
+func NormalizeListUsersInput(input ListUsersInput) ListUsersInput {
+	if input.Limit <= 0 {
+		input.Limit = defaultUserPageLimit
+	}
+	if input.Limit > maxUserPageLimit {
+		input.Limit = maxUserPageLimit
+	}
+	if input.Offset < 0 {
+		input.Offset = 0
+	}
+	return input
+}
+
+// ListUsers returns a page of all accounts for administrators.
+func (s *Service) ListUsers(ctx context.Context, input ListUsersInput) ([]User, error) {
+	input = NormalizeListUsersInput(input)
+
+	users, err := s.rep.ListUsers(ctx, input.Limit, input.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("service list users: %w", err)
+	}
+	return users, nil
+}
+
+// DeactivateUser disables another user's account and revokes all of their tokens.
+func (s *Service) DeactivateUser(ctx context.Context, actorID, userID int64) error {
+	if actorID == userID {
+		return ErrCannotModifySelf
+	}
+
+	if err := s.rep.DeactivateUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.invalidateTokens(ctx, userID); err != nil {
+		return err
+	}
+
+	// Forget the cached cutoff so the next request sees the account as inactive.
+	s.tokenCutoffs.Delete(userID)
+	return nil
+}
+
+// SetUserRole changes another user's role. Existing tokens are invalidated so the new role
+// takes effect immediately instead of when the old access token expires.
+func (s *Service) SetUserRole(ctx context.Context, actorID, userID int64, role Role) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+	if actorID == userID {
+		return ErrCannotModifySelf
+	}
+
+	if err := s.rep.SetUserRole(ctx, userID, role); err != nil {
+		return err
+	}
+	return s.invalidateTokens(ctx, userID)
+}
+
+// UnlockUser clears the failed sign-in streak and any lockout of an account.
+func (s *Service) UnlockUser(ctx context.Context, actorID, userID int64) error {
+	user, err := s.rep.GetAnyByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.clearAccountThrottle(ctx, user.Email); err != nil {
+		return err
+	}
+
+	s.recordEvent(ctx, user.ID, EventAccountUnlocked, map[string]any{
+		"unlocked_by": actorID,
+	})
+	return nil
+}
+
+// ListSecurityEvents returns a page of the user's own audit log, newest first.
+func (s *Service) ListSecurityEvents(ctx context.Context, userID int64, input ListUsersInput) ([]AuthEvent, error) {
+	input = NormalizeListUsersInput(input)
+
+	events, err := s.rep.ListUserAuthEventsPage(ctx, userID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("service list security events: %w", err)
+	}
+	return events, nil
+}
+
+// SearchAuthEvents returns a page of the audit log across all users for administrators.
+func (s *Service) SearchAuthEvents(ctx context.Context, filter AuthEventFilter) ([]AuthEvent, error) {
+	page := NormalizeListUsersInput(ListUsersInput{Limit: filter.Limit, Offset: filter.Offset})
+	filter.Limit, filter.Offset = page.Limit, page.Offset
+
+	events, err := s.rep.SearchAuthEvents(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("service search auth events: %w", err)
+	}
+	return events, nil
+}
+
+// recordEvent appends an entry to the auth audit log using the request's client details.
+// Audit failures are logged rather than returned so they never block authentication itself.
+func (s *Service) recordEvent(ctx context.Context, userID int64, eventType string, metadata map[string]any) {
+	client := ClientInfoFromContext(ctx)
+	err := s.rep.CreateAuthEvent(ctx, AuthEvent{
+		UserID:    userID,
+		Type:      eventType,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		RequestID: client.RequestID,
+		Metadata:  metadata,
+	})
+	if err != nil {
+		log.Printf("record auth event %s: %v", eventType, err)
+	}
+}
+
 // GetMe returns the full user profile for the given email address.
 func (s *Service) GetMe(ctx context.Context, email string) (User, error) {
 
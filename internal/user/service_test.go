@@ -0,0 +1,246 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OnatArslan/devlog/internal/keyring"
+	"github.com/OnatArslan/devlog/internal/mailer"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newTestService returns a service without a repository, for flows that only sign tokens and
+// send email. Its mailer records every message.
+func newTestService(t *testing.T) (*Service, *mailer.MemoryMailer) {
+	t.Helper()
+	keys, err := keyring.Ephemeral()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mail := mailer.NewMemoryMailer()
+	svc := NewUserService(nil, Config{
+		Mailer:         mail,
+		AppURL:         "https://devlog.example.com/",
+		Keys:           keys,
+		PasswordHasher: NewArgon2idHasher(testArgon2idParams),
+	})
+	return svc, mail
+}
+
+func TestSendVerificationEmail(t *testing.T) {
+	svc, mail := newTestService(t)
+
+	if err := svc.sendVerificationEmail(context.Background(), User{ID: 7, Email: "jane@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, ok := mail.Last()
+	if !ok {
+		t.Fatal("no verification email was sent")
+	}
+	if msg.To != "jane@example.com" {
+		t.Errorf("email sent to %q, want jane@example.com", msg.To)
+	}
+
+	const prefix = "https://devlog.example.com/api/v1/users/verify?token="
+	i := strings.Index(msg.Body, prefix)
+	if i < 0 {
+		t.Fatalf("email body has no verification link:\n%s", msg.Body)
+	}
+	link, err := url.Parse(strings.Fields(msg.Body[i:])[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := link.Query().Get("token")
+
+	var claims verificationClaims
+	if err := svc.parseToken(token, audienceEmailVerification, &claims); err != nil {
+		t.Fatalf("verification token does not parse: %v", err)
+	}
+	if claims.Subject != "7" || claims.Email != "jane@example.com" {
+		t.Errorf("verification token claims subject %q and email %q", claims.Subject, claims.Email)
+	}
+	if err := svc.parseToken(token, audienceAccountRestore, &verificationClaims{}); err == nil {
+		t.Error("verification token was accepted for another audience")
+	}
+}
+
+// failingMailer refuses every message, like an unreachable SMTP server.
+type failingMailer struct{}
+
+func (failingMailer) Send(context.Context, mailer.Message) error {
+	return errors.New("smtp: connection refused")
+}
+
+func TestResendVerificationRetriesAfterFailedSend(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	svc, mail := newTestService(t)
+	svc.rep = NewUserRepository(pool)
+
+	_, err := pool.Exec(ctx, `INSERT INTO users (email, username, password_hash, is_active)
+		VALUES ('jane@example.com', 'jane', 'unused', FALSE)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc.mailer = failingMailer{}
+	if err := svc.ResendVerification(ctx, "jane@example.com"); err == nil {
+		t.Fatal("ResendVerification succeeded although the mailer failed")
+	}
+
+	svc.mailer = mail
+	if err := svc.ResendVerification(ctx, "jane@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mail.Last(); !ok {
+		t.Error("no verification email was sent on the retry after a failed send")
+	}
+}
+
+func TestCheckTokenIssuedAt(t *testing.T) {
+	svc, _ := newTestService(t)
+	cutoff := time.Date(2026, 3, 1, 12, 0, 0, 500_000_000, time.UTC)
+	svc.tokenCutoffs.Set(7, cutoff)
+
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		wantErr  error
+	}{
+		{"before leeway", cutoff.Add(-tokenCutoffLeeway - time.Second), ErrTokenRevoked},
+		{"within leeway", cutoff.Add(-time.Second), nil},
+		{"same second as cutoff", cutoff.Truncate(time.Second), nil},
+		{"later second", cutoff.Add(time.Second), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.CheckTokenIssuedAt(context.Background(), 7, tt.issuedAt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckTokenIssuedAt(%v) = %v, want %v", tt.issuedAt, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckSessionRejectsRevokedSession(t *testing.T) {
+	svc, _ := newTestService(t)
+	svc.sessions.Set("revoked", false)
+
+	if err := svc.CheckSession(context.Background(), 7, "revoked"); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("CheckSession = %v, want %v", err, ErrTokenRevoked)
+	}
+}
+
+func TestChangePasswordKeepsNewSession(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	svc.rep = NewUserRepository(pool)
+	h := NewUserHandler(svc, nil)
+
+	const password = "correct horse battery staple"
+	passwordHash, err := svc.hasher.Hash(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var userID int64
+	err = pool.QueryRow(ctx, `INSERT INTO users (email, username, password_hash, is_active, email_verified_at)
+		VALUES ('jane@example.com', 'jane', $1, TRUE, now()) RETURNING id`, passwordHash).Scan(&userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := svc.SignIn(ctx, SignInInput{Email: "jane@example.com", Password: password})
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := svc.ChangePassword(ctx, userID, password, "a brand new passphrase for jane")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each check runs on a service with cold caches as well, the way another replica sees it.
+	replica, _ := newTestService(t)
+	replica.keys = svc.keys
+	replica.rep = svc.rep
+	for name, h := range map[string]*Handler{"this replica": h, "other replica": NewUserHandler(replica, nil)} {
+		if code := authStatus(h, after.Token); code != http.StatusOK {
+			t.Errorf("%s: new token status = %d, want %d", name, code, http.StatusOK)
+		}
+		if code := authStatus(h, before.Token); code != http.StatusUnauthorized {
+			t.Errorf("%s: old token status = %d, want %d", name, code, http.StatusUnauthorized)
+		}
+	}
+}
+
+// authStatus returns the status AuthMiddleware answers a request bearing token with.
+func authStatus(h *Handler, token string) int {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	req := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	h.AuthMiddleware(next).ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestTwoFactorChallengeDefersRehash(t *testing.T) {
+	svc, _ := newTestService(t)
+	oldHash, err := NewBcryptHasher(4).Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := User{ID: 7, PasswordHash: oldHash}
+
+	challengeID := func(out SignInOutput) string {
+		t.Helper()
+		claims := jwt.RegisteredClaims{}
+		if err := svc.parseToken(out.ChallengeToken, audienceTwoFactor, &claims); err != nil {
+			t.Fatal(err)
+		}
+		if claims.ID == "" {
+			t.Fatal("challenge token has no jti")
+		}
+		return claims.ID
+	}
+
+	out, err := svc.issueTwoFactorChallenge(context.Background(), user, "correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := challengeID(out)
+
+	var pending pendingRehash
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var ok bool
+		if pending, ok = svc.rehashes.Get(id); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no upgraded hash was prepared for the challenge")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if pending.userID != user.ID || pending.oldHash != oldHash {
+		t.Errorf("pending rehash = %+v, want user %d and the old hash", pending, user.ID)
+	}
+	if ok, err := svc.hasher.Verify(pending.newHash, "correct horse battery staple"); err != nil || !ok {
+		t.Errorf("upgraded hash does not verify the password: ok=%v err=%v", ok, err)
+	}
+
+	// Without a password, as after a magic link, there is nothing to upgrade from.
+	out, err = svc.issueTwoFactorChallenge(context.Background(), user, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := svc.rehashes.Get(challengeID(out)); ok {
+		t.Error("a rehash was prepared without a password")
+	}
+}
@@ -0,0 +1,141 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// throttlePolicy describes when repeated failures for one key start to slow down and lock.
+type throttlePolicy struct {
+	// backoffAfter is the failure count after which each attempt must wait exponentially longer.
+	backoffAfter int
+	// lockAfter is the failure count that locks the key for lockFor.
+	lockAfter int
+	lockFor   time.Duration
+}
+
+// Accounts are protected tightly; a single IP may legitimately serve many users behind NAT,
+// so it gets more headroom before it is slowed down.
+var (
+	accountThrottle = throttlePolicy{backoffAfter: 3, lockAfter: 10, lockFor: 15 * time.Minute}
+	ipThrottle      = throttlePolicy{backoffAfter: 20, lockAfter: 100, lockFor: 15 * time.Minute}
+)
+
+const (
+	// throttleWindow is how long a failure streak is remembered after its last failure.
+	throttleWindow = time.Hour
+	// maxThrottleBackoff caps the exponential delay between attempts.
+	maxThrottleBackoff = 5 * time.Minute
+)
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// wait returns how long the caller must wait before another attempt for t is allowed, and
+// whether that wait comes from a lockout rather than from backoff.
+func (p throttlePolicy) wait(t AuthThrottle, now time.Time) (time.Duration, bool) {
+	if t.LockedUntil.After(now) {
+		return t.LockedUntil.Sub(now), true
+	}
+	if t.LastFailureAt.Before(now.Add(-throttleWindow)) || t.Failures < p.backoffAfter {
+		return 0, false
+	}
+
+	delay := maxThrottleBackoff
+	if shift := t.Failures - p.backoffAfter; shift < 16 {
+		delay = min(time.Second<<shift, maxThrottleBackoff)
+	}
+	if next := t.LastFailureAt.Add(delay); next.After(now) {
+		return next.Sub(now), false
+	}
+	return 0, false
+}
+
+// checkSignInThrottle rejects an attempt for email from ip while either key is backing off or locked.
+// It runs before any password hashing so throttled callers cost almost nothing.
+func (s *Service) checkSignInThrottle(ctx context.Context, email, ip string) error {
+	keys := []string{accountThrottleKey(email)}
+	if ip != "" {
+		keys = append(keys, ipThrottleKey(ip))
+	}
+
+	throttles, err := s.rep.GetAuthThrottles(ctx, keys)
+	if err != nil {
+		return fmt.Errorf("service check throttle: %w", err)
+	}
+
+	now := time.Now()
+	var longest time.Duration
+	var locked bool
+	for _, t := range throttles {
+		policy := accountThrottle
+		if strings.HasPrefix(t.Key, "ip:") {
+			policy = ipThrottle
+		}
+		if wait, isLock := policy.wait(t, now); wait > longest {
+			longest, locked = wait, isLock
+		}
+	}
+
+	switch {
+	case longest == 0:
+		return nil
+	case locked:
+		return &RetryAfterError{Err: ErrAccountLocked, RetryAfter: longest}
+	default:
+		return &RetryAfterError{Err: ErrTooManyAttempts, RetryAfter: longest}
+	}
+}
+
+// recordSignInFailure audits a failed attempt, counts it against the account and the IP and locks
+// either key once it crosses its policy's threshold. userID is zero when no account matched email.
+func (s *Service) recordSignInFailure(ctx context.Context, email, ip string, userID int64, reason string) error {
+	s.recordEvent(ctx, userID, EventSignInFailure, map[string]any{"email": email, "reason": reason})
+
+	if err := s.recordThrottleFailure(ctx, accountThrottleKey(email), accountThrottle, userID); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return s.recordThrottleFailure(ctx, ipThrottleKey(ip), ipThrottle, userID)
+}
+
+func (s *Service) recordThrottleFailure(ctx context.Context, key string, policy throttlePolicy, userID int64) error {
+	now := time.Now()
+	t, err := s.rep.RecordAuthFailure(ctx, key, now.Add(-throttleWindow))
+	if err != nil {
+		return fmt.Errorf("service record auth failure: %w", err)
+	}
+
+	if t.Failures < policy.lockAfter || t.LockedUntil.After(now) {
+		return nil
+	}
+
+	lockedUntil := now.Add(policy.lockFor)
+	if err := s.rep.LockAuthThrottle(ctx, key, lockedUntil); err != nil {
+		return fmt.Errorf("service lock auth throttle: %w", err)
+	}
+
+	s.recordEvent(ctx, userID, EventLockout, map[string]any{
+		"key":          key,
+		"failures":     t.Failures,
+		"locked_until": lockedUntil,
+	})
+	return nil
+}
+
+// clearAccountThrottle forgets the failure streak of an account after a successful sign-in or an admin unlock.
+func (s *Service) clearAccountThrottle(ctx context.Context, email string) error {
+	if err := s.rep.ClearAuthThrottle(ctx, accountThrottleKey(email)); err != nil {
+		return fmt.Errorf("service clear auth throttle: %w", err)
+	}
+	return nil
+}
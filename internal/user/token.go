@@ -0,0 +1,96 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tokenIssuer is the iss claim on every JWT devlog signs; verifiers elsewhere can pin it alongside the JWKS.
+const tokenIssuer = "devlog-auth-service"
+
+// JWT audiences keep tokens minted for one purpose from being accepted by another.
+const (
+	audienceAccess            = "devlog-api"
+	audienceEmailVerification = "email-verification"
+	audienceTwoFactor         = "two-factor-challenge"
+	audienceEmailChange       = "email-change"
+	audienceAccountRestore    = "account-restore"
+)
+
+// signToken signs claims with the keyring's active key.
+func (s *Service) signToken(claims jwt.Claims) (string, error) {
+	return s.keys.Sign(claims)
+}
+
+// parseToken verifies tokenStr against any key in the keyring for the given audience and decodes it into claims.
+// It returns ErrInvalidToken for any signature, key, expiry, or audience failure.
+func (s *Service) parseToken(tokenStr, audience string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(tokenStr, claims, s.keys.Keyfunc,
+		jwt.WithValidMethods(s.keys.Algorithms()),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithAudience(audience),
+		jwt.WithIssuedAt())
+	if err != nil || !token.Valid {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// newOpaqueToken returns a random URL-safe token together with the hash that should be persisted.
+func newOpaqueToken() (raw string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	raw = base64.RawURLEncoding.EncodeToString(b)
+	return raw, hashToken(raw), nil
+}
+
+// personalAccessTokenPrefix marks personal access tokens so middleware can tell them apart from JWTs
+// and secret scanners can recognise leaked ones.
+const personalAccessTokenPrefix = "dlp_"
+
+// newPersonalAccessToken returns a random personal access token, its displayable prefix, and its hash.
+func newPersonalAccessToken() (raw string, prefix string, hash string, err error) {
+	secret, _, err := newOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	raw = personalAccessTokenPrefix + secret
+	return raw, raw[:len(personalAccessTokenPrefix)+8], hashToken(raw), nil
+}
+
+// inviteCodePrefix marks invite codes so they cannot be mistaken for other opaque tokens.
+const inviteCodePrefix = "dli_"
+
+// newInviteCode returns a random invite code, its displayable prefix, and its hash.
+func newInviteCode() (raw string, prefix string, hash string, err error) {
+	secret, _, err := newOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	raw = inviteCodePrefix + secret
+	return raw, raw[:len(inviteCodePrefix)+8], hashToken(raw), nil
+}
+
+// hashToken returns the hex SHA-256 digest used to look up opaque tokens without storing them.
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// newFamilyID returns a random RFC 4122 version 4 UUID string for a new refresh token family.
+func newFamilyID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
@@ -0,0 +1,125 @@
+package user
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters follow the RFC 6238 defaults that every authenticator app supports.
+const (
+	totpPeriod    = 30
+	totpDigits    = 6
+	totpSkewSteps = 1
+	totpIssuer    = "devlog"
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newTOTPSecret returns a random 160-bit shared secret encoded as unpadded base32.
+func newTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// totpURI builds the otpauth:// provisioning URI that authenticator apps read from a QR code.
+func totpURI(account, secret string) string {
+	label := url.PathEscape(totpIssuer + ":" + account)
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", totpIssuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(totpPeriod))
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// totpCode computes the HOTP value (RFC 4226) of secret for the given time step.
+func totpCode(secret []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation picks four bytes based on the low nibble of the last byte.
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range totpDigits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+// validateTOTP checks code against the secret around now, tolerating one step of clock skew.
+// It returns the matched time step so callers can refuse to accept the same step twice.
+func validateTOTP(secret, code string, now time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for delta := int64(-totpSkewSteps); delta <= totpSkewSteps; delta++ {
+		step := current + delta
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// recoveryCodeCount is how many one-time recovery codes are issued when 2FA is enabled.
+const recoveryCodeCount = 10
+
+// newRecoveryCodes returns human-friendly one-time codes such as "k7q2m-xp4ta".
+func newRecoveryCodes() ([]string, error) {
+	// 32 symbols without i, l, or o, so each random byte maps to a symbol without bias.
+	const alphabet = "abcdefghjkmnpqrstuvwxyz023456789"
+
+	codes := make([]string, 0, recoveryCodeCount)
+	buf := make([]byte, 10)
+	for range recoveryCodeCount {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		for i, c := range buf {
+			if i == 5 {
+				b.WriteByte('-')
+			}
+			b.WriteByte(alphabet[c&31])
+		}
+		codes = append(codes, b.String())
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode strips formatting so "K7Q2M XP4TA" matches "k7q2m-xp4ta".
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
@@ -1,8 +1,10 @@
 package validatorx
 
 import (
+	"net/url"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -12,6 +14,11 @@ func New() *validator.Validate {
 	v := validator.New(validator.WithRequiredStructEnabled())
 	// Register all custom validators in here
 	v.RegisterValidation("strong-password", strongPassword)
+	v.RegisterValidation("username", username)
+	v.RegisterValidation("display-name", displayName)
+	v.RegisterValidation("plain-text", plainText)
+	v.RegisterValidation("web-url", webURL)
+	v.RegisterValidation("social-platform", socialPlatform)
 	return v
 }
 
@@ -40,3 +47,72 @@ func strongPassword(fl validator.FieldLevel) bool {
 
 	return hasUpper && hasLower && hasDigit && hasSpecial
 }
+
+// reservedUsernames collide with fixed routes under /users, so profiles with these names could
+// never be reached.
+var reservedUsernames = map[string]bool{
+	"admin": true, "email": true, "me": true, "password": true, "signin": true,
+	"signup": true, "token": true, "verify": true,
+}
+
+// username accepts 3-30 ASCII letters and digits that are not a reserved route name.
+func username(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	if len(s) < 3 || len(s) > 30 || reservedUsernames[strings.ToLower(s)] {
+		return false
+	}
+	for _, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// displayName accepts up to 50 printable characters without surrounding whitespace.
+func displayName(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	if utf8.RuneCountInString(s) > 50 || strings.TrimSpace(s) != s {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// plainText rejects control characters other than newlines and tabs, e.g. in a bio.
+func plainText(fl validator.FieldLevel) bool {
+	for _, r := range fl.Field().String() {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// webURL accepts absolute http and https URLs with a host, up to 200 bytes.
+func webURL(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	if len(s) > 200 {
+		return false
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.User == nil
+}
+
+// socialPlatforms lists the networks a profile may link to.
+var socialPlatforms = map[string]bool{
+	"bluesky": true, "github": true, "gitlab": true, "linkedin": true,
+	"mastodon": true, "x": true, "youtube": true,
+}
+
+// socialPlatform accepts the name of a supported social network.
+func socialPlatform(fl validator.FieldLevel) bool {
+	return socialPlatforms[fl.Field().String()]
+}